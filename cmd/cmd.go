@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -10,13 +12,16 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/cedrickchee/go-parkinglot/internal/printer"
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
 )
 
 type RunOptions struct {
 	Stdin  io.Reader
 	Stdout io.Writer
+	Clock  parkinglot.Clock // The system clock if nil
 }
 
 func Run(args []string) {
@@ -35,118 +40,749 @@ func RunCustom(args []string, runOpts *RunOptions) {
 		runOpts.Stdout = os.Stdout
 	}
 
-	argsLen := len(args)
+	name := "parking_lot"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	cmdFlags := flag.NewFlagSet(name, flag.ContinueOnError)
+	cmdFlags.SetOutput(runOpts.Stdout)
+	tariffFile := cmdFlags.String("tariff", "", "Tariff config `file` to charge vehicles by")
+	plateFormatName := cmdFlags.String("plate_format", parkinglot.DefaultPlateFormat, "Registration number `format` to validate and normalize plates by")
+	colorFile := cmdFlags.String("colours", "", "Colours config `file` with the aliases of each colour")
+	strictColors := cmdFlags.Bool("strict_colours", false, "Reject unknown colours")
+	waitlist := cmdFlags.Bool("waitlist", false, "Put vehicles on a waitlist when the parking lot is full")
+	operator := cmdFlags.String("operator", "", "`name` of the operator on duty, recorded in the history")
+	fakeTime := cmdFlags.String("fake_time", "", "Start a fake clock at `time`, such as \"2026-10-17 09:00:00\", which only advance_time moves")
+	noShowAfter := cmdFlags.Duration("no_show_after", parkinglot.DefaultNoShowAfter, "How long after the start of its window a reservation holds its slot")
+	allocatorName := cmdFlags.String("allocator", parkinglot.DefaultAllocator, "Slot allocation `strategy` of parking lots created without one")
+	stateFile := cmdFlags.String("state", "", "Snapshot `file` to restore the parking lot from at startup, if it exists, and to save it to on exit")
+	journalFile := cmdFlags.String("journal", "", "Journal `file` to record every change to the parking lot in, and to rebuild it from at startup")
+	if err := cmdFlags.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	defaultNewAllocator, err := parkinglot.LookupAllocator(*allocatorName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	argsLen := cmdFlags.NArg()
 
 	var scanner *bufio.Scanner
 
 	switch {
-	case argsLen == 2:
-		inputFile, err := os.Open(args[1])
+	case argsLen == 1:
+		inputFile, err := os.Open(cmdFlags.Arg(0))
 		if err != nil {
 			panic(err)
 		}
 		defer inputFile.Close()
 		scanner = bufio.NewScanner(inputFile)
-	case argsLen > 2:
+	case argsLen > 1:
 		log.Fatal("Unknown command line input")
 	default:
 		scanner = bufio.NewScanner(runOpts.Stdin)
 	}
 
-	// Create a parking lot
-	var parkinglot = &ParkingLot{}
+	clock := runOpts.Clock
+	if *fakeTime != "" {
+		t, err := parseTime(*fakeTime)
+		if err != nil {
+			log.Fatal(err)
+		}
+		clock = parkinglot.NewFakeClock(t)
+	}
+	if *noShowAfter <= 0 {
+		log.Fatal("No-show time must be greater than zero")
+	}
+
+	plateFormat, err := parkinglot.LookupPlateFormat(*plateFormatName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	colors := parkinglot.NewColorRegistry(parkinglot.DefaultColors)
+	if *colorFile != "" {
+		colors, err = parkinglot.LoadColorRegistry(*colorFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	colors.SetStrict(*strictColors)
+
+	var tariff *parkinglot.Tariff
+	if *tariffFile != "" {
+		tariff, err = parkinglot.LoadTariff(*tariffFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Create a parking lot, laid out later by create_parking_lot
+	options := parkinglot.Options{
+		Clock:       clock,
+		Operator:    *operator,
+		PlateFormat: plateFormat,
+		Colors:      colors,
+		Tariff:      tariff,
+		Waitlist:    *waitlist,
+		NoShowAfter: *noShowAfter,
+	}
+	lot := parkinglot.New(&options)
+	s := &session{lot: lot, out: runOpts.Stdout, defaultNewAllocator: defaultNewAllocator}
+
+	// The journal holds every change since its first record, so the state
+	// file is only read when the journal is empty
+	var records []journalRecord
+	if *journalFile != "" {
+		s.journal, records, err = openJournal(*journalFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer s.journal.Close()
+		if err := replayJournal(lot, options, defaultNewAllocator, records); err != nil {
+			log.Fatal(errorMessage(err))
+		}
+	}
+	if *stateFile != "" && len(records) == 0 {
+		if err := loadSnapshotFile(lot, *stateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatal(errorMessage(err))
+		}
+		if s.journal != nil && lot.Capacity() > 0 {
+			if err := s.journal.compact(lot, lot.Clock().Now()); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	for scanner.Scan() {
+		if s.execute(scanner.Text()) {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if *stateFile != "" && lot.Capacity() > 0 {
+		if err := saveSnapshotFile(lot, *stateFile); err != nil {
+			log.Fatal(errorMessage(err))
+		}
+	}
+}
 
-	exit := false
-	for !exit && scanner.Scan() {
-		input := scanner.Text()
+// A session runs commands on a parking lot and prints their results
+type session struct {
+	lot                 *parkinglot.ParkingLot
+	out                 io.Writer
+	defaultNewAllocator parkinglot.NewAllocator
+	journal             *journal // Records the commands that change the lot, if not nil
+}
 
-		cmdArgs := parse(input)
+// Run a command, and report whether it asks to exit
+func (s *session) execute(input string) (exit bool) {
+	lot := s.lot
+	cmdArgs, flags := parseFlags(parse(input))
+	if s.journal != nil && len(cmdArgs) > 0 && journaledCommands[cmdArgs[0]] {
+		// Record the command before running it, so that it is on disk
+		// before its result is printed
+		if err := s.journal.append(lot.Clock().Now(), input); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-		switch {
-		case validate(cmdArgs, "create_parking_lot", 2):
-			capacity, err := strconv.Atoi(cmdArgs[1])
+	switch {
+	case validate(cmdArgs, "create_parking_lot", 2):
+		// The capacity is either a single number or a comma separated
+		// list with the capacity of each floor
+		capacities, err := parseIntList(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		layouts := parkinglot.StackedFloors(capacities)
+		if value, ok := flags["distances"]; ok {
+			distances, err := parseIntList(value)
 			if err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
 				break
 			}
-			if err := parkinglot.createParkingLot("Marina Bay Sands", capacity); err == nil {
-				fmt.Fprintf(runOpts.Stdout, "Created a parking lot with %v slots\n", capacity)
-			} else {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
+			if len(distances) != len(layouts) {
+				fmt.Fprintln(s.out, "Number of distances does not match number of floors")
+				break
 			}
-
-		case validate(cmdArgs, "park", 3):
-			slot, err := parkinglot.park(cmdArgs[1], cmdArgs[2])
+			for i := range layouts {
+				layouts[i].Distance = distances[i]
+			}
+		}
+		if value, ok := flags["exit_distances"]; ok {
+			distances, err := parseIntList(value)
 			if err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
-			} else {
-				fmt.Fprintf(runOpts.Stdout, "Allocated slot number: %v\n", slot.getParkingSlotNumber())
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
 			}
-
-		case validate(cmdArgs, "leave", 2):
-			slotNumber, err := strconv.Atoi(cmdArgs[1])
+			if len(distances) != len(layouts) {
+				fmt.Fprintln(s.out, "Number of exit distances does not match number of floors")
+				break
+			}
+			for i := range layouts {
+				layouts[i].ExitDistance = distances[i]
+			}
+		}
+		if value, ok := flags["sizes"]; ok {
+			sizes, err := parseSlotSizes(value)
 			if err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
 				break
 			}
-			if err := parkinglot.leave(slotNumber); err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
-			} else {
-				fmt.Fprintf(runOpts.Stdout, "Slot number %v is free\n", slotNumber)
+			if err := applySlotSizes(layouts, sizes); err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
 			}
-
-		case validate(cmdArgs, "status", 1):
-			slots := parkinglot.getStatus()
-			var w = tabwriter.NewWriter(runOpts.Stdout, 0, 0, 4, ' ', 0)
-			fmt.Fprintln(w, "Slot No.\tRegistration No\tColour")
-			for _, slot := range slots {
-				vehicle := slot.getVehicle()
-				s := fmt.Sprintf("%v\t%s\t%s", slot.getParkingSlotNumber(), vehicle.getNumber(), vehicle.getColor())
-				fmt.Fprintln(w, s)
+		}
+		if value, ok := flags["attributes"]; ok {
+			attributes, err := parseSlotAttributes(value, sumCapacity(layouts))
+			if err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+			applySlotAttributes(layouts, attributes)
+		}
+		policy := parkinglot.ExactSize
+		if value, ok := flags["size_policy"]; ok {
+			policy, err = parkinglot.ParseSizePolicy(value)
+			if err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+		}
+		newAllocator := s.defaultNewAllocator
+		if value, ok := flags["allocator"]; ok {
+			newAllocator, err = parkinglot.LookupAllocator(value)
+			if err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+		}
+		var gates []gateLayout
+		if value, ok := flags["gates"]; ok {
+			gates, err = parseGates(value, sumCapacity(layouts))
+			if err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+		}
+		if err := lot.Create("Marina Bay Sands", layouts, &parkinglot.CreateOptions{SizePolicy: policy, Allocator: newAllocator}); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		for _, gate := range gates {
+			if err := lot.AddGate(gate.name, parkinglot.GateDistances(lot.Slots(), gate.slotNumber)); err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+			}
+		}
+		if s.journal != nil {
+			// Replaying the command would seed a random allocator afresh,
+			// so the journal starts over from the new parking lot
+			if err := s.journal.compact(lot, lot.Clock().Now()); err != nil {
+				log.Fatal(err)
 			}
-			w.Flush()
+		}
+		if len(layouts) == 1 {
+			fmt.Fprintf(s.out, "Created a parking lot with %v slots\n", lot.Capacity())
+		} else {
+			fmt.Fprintf(s.out, "Created a parking lot with %v slots on %v floors\n", lot.Capacity(), len(layouts))
+		}
 
-		case validate(cmdArgs, "registration_numbers_for_cars_with_colour", 2):
-			_, regisNumbers, err := parkinglot.getVehiclesByColor(cmdArgs[1])
+	case validate(cmdArgs, "park", 3), validate(cmdArgs, "park", 4),
+		validate(cmdArgs, "park_override", 3), validate(cmdArgs, "park_override", 4):
+		// The vehicle type is optional and defaults to a car
+		vehicleType := parkinglot.Car
+		if len(cmdArgs) == 4 {
+			var err error
+			vehicleType, err = parkinglot.ParseVehicleType(cmdArgs[3])
 			if err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
 				break
 			}
-			err = printer.Fprintf(runOpts.Stdout, regisNumbers)
+		}
+		var needs parkinglot.Attributes
+		if value, ok := flags["needs"]; ok {
+			var err error
+			needs, err = parkinglot.ParseAttributes(value)
 			if err != nil {
-				panic(err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+		}
+		vehicle := parkinglot.NewVehicle(cmdArgs[1], cmdArgs[2], vehicleType, needs)
+		var ticket *parkinglot.Ticket
+		var err error
+		if cmdArgs[0] == "park_override" {
+			// Parks a vehicle even if its registration number is already parked
+			ticket, err = lot.OverridePark(vehicle, flags["gate"], flags["reason"])
+		} else {
+			ticket, err = lot.ParkAtGate(vehicle, flags["gate"])
+		}
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+		} else {
+			fmt.Fprintf(s.out, "Allocated slot number: %v\n", slotLabel(lot, ticket.Slots()[0]))
+			fmt.Fprintf(s.out, "Ticket number: %v, entry time: %v\n", ticket.TicketNumber(), formatTime(ticket.EntryTime()))
+		}
+
+	case validate(cmdArgs, "leave", 2):
+		slotNumber, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		ticket, err := lot.Leave(slotNumber)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		printFreedSlots(s.out, ticket)
+		printFee(s.out, lot, ticket)
+		printAssigned(s.out, lot, ticket)
+
+	case validate(cmdArgs, "leave_ticket", 2):
+		ticketNumber, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		ticket, err := lot.LeaveByTicket(ticketNumber)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		printFreedSlots(s.out, ticket)
+		fmt.Fprintf(s.out, "Exit time: %v, duration: %v\n", formatTime(ticket.ExitTime()), ticket.Duration())
+		printFee(s.out, lot, ticket)
+		printAssigned(s.out, lot, ticket)
+
+	case validate(cmdArgs, "ticket", 2):
+		ticketNumber, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		ticket, err := lot.Ticket(ticketNumber)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		vehicle := ticket.Vehicle()
+		fmt.Fprintf(s.out, "Ticket number: %v, registration number: %v, slot number: %v, entry time: %v",
+			ticket.TicketNumber(), vehicle.RegistrationNumber(), slotsLabel(lot, ticket.Slots()), formatTime(ticket.EntryTime()))
+		if ticket.IsClosed() {
+			fmt.Fprintf(s.out, ", exit time: %v, duration: %v", formatTime(ticket.ExitTime()), ticket.Duration())
+			if lot.Tariff() != nil {
+				fmt.Fprintf(s.out, ", fee: %v", parkinglot.FormatFee(ticket.Fee()))
 			}
+			fmt.Fprintln(s.out)
+		} else {
+			fmt.Fprintln(s.out, ", parked")
+		}
 
-		case validate(cmdArgs, "slot_numbers_for_cars_with_colour", 2):
-			slotNumbers, _, err := parkinglot.getVehiclesByColor(cmdArgs[1])
+	case validate(cmdArgs, "advance_time", 2):
+		d, err := time.ParseDuration(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		clock, ok := lot.Clock().(parkinglot.Advancer)
+		if !ok {
+			fmt.Fprintln(s.out, "Clock cannot be advanced")
+			break
+		}
+		if d < 0 {
+			fmt.Fprintln(s.out, "Time cannot go backwards")
+			break
+		}
+		clock.Advance(d)
+		fmt.Fprintf(s.out, "Time: %v\n", formatTime(lot.Clock().Now()))
+
+	case validate(cmdArgs, "operator", 2):
+		lot.SetOperator(cmdArgs[1])
+
+	case validate(cmdArgs, "history_for_registration_number", 2):
+		events, err := lot.HistoryForRegistrationNumber(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		for _, event := range events {
+			printEvent(s.out, lot, event)
+		}
+
+	case validate(cmdArgs, "history_for_slot", 2):
+		slotNumber, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		events, err := lot.HistoryForSlot(slotNumber)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		for _, event := range events {
+			printEvent(s.out, lot, event)
+		}
+
+	case validate(cmdArgs, "vehicle_in_slot_at", 4):
+		// The point in time is a date and a time of day, such as 2026-10-17 09:30:00
+		slotNumber, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		t, err := parseTime(cmdArgs[2] + " " + cmdArgs[3])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		event, err := lot.VehicleInSlotAt(slotNumber, t)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		printEvent(s.out, lot, event)
+
+	case validate(cmdArgs, "waitlist", 1):
+		entries, err := lot.Waitlist()
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(s.out, "Waitlist is empty")
+		}
+		for i, entry := range entries {
+			vehicle := entry.Vehicle()
+			fmt.Fprintf(s.out, "Position: %v, registration number: %v, colour: %v, waiting since: %v",
+				i+1, vehicle.RegistrationNumber(), vehicle.Color(), formatTime(entry.Since()))
+			if entry.IsPriority() {
+				fmt.Fprint(s.out, ", permit holder")
+			}
+			fmt.Fprintln(s.out)
+		}
+
+	case validate(cmdArgs, "cancel_wait", 2):
+		if err := lot.CancelWait(cmdArgs[1]); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Registration number %v left the waitlist\n", cmdArgs[1])
+
+	case validate(cmdArgs, "add_permit", 4):
+		// The permit is valid from the first day to the last, such as
+		// 2026-10-01 2026-10-31, in a dedicated slot if one is given
+		validFrom, err := parseDate(cmdArgs[2])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		validUntil, err := parseDate(cmdArgs[3])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		slotNumber := 0
+		if value, ok := flags["slot"]; ok {
+			slotNumber, err = strconv.Atoi(value)
 			if err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
 				break
 			}
-			err = printer.Fprintf(runOpts.Stdout, slotNumbers)
+			if slotNumber <= 0 {
+				fmt.Fprintln(s.out, "Invalid slot number")
+				break
+			}
+		}
+		permit, err := lot.AddPermit(cmdArgs[1], validFrom, validUntil, slotNumber)
+		if errors.Is(err, parkinglot.ErrSlotDedicated) {
+			fmt.Fprintln(s.out, "Slot is already dedicated to a permit")
+			break
+		}
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Added permit for %v in %v\n", permit.RegistrationNumber(), permitLabel(lot, permit))
+
+	case validate(cmdArgs, "revoke_permit", 2):
+		if err := lot.RevokePermit(cmdArgs[1]); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Revoked permit for %v\n", cmdArgs[1])
+
+	case validate(cmdArgs, "permit_pool", 2):
+		size, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if err := lot.SetPermitPool(size); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Reserved %v slots for floating permits\n", size)
+
+	case validate(cmdArgs, "permits", 1):
+		permits, err := lot.Permits()
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if len(permits) == 0 {
+			fmt.Fprintln(s.out, "No permits")
+		}
+		for _, permit := range permits {
+			fmt.Fprintf(s.out, "Registration number: %v, valid from: %v, valid until: %v, %v\n",
+				permit.RegistrationNumber(), formatDate(permit.ValidFrom()), formatDate(permit.ValidUntil()),
+				permitLabel(lot, permit))
+		}
+
+	case validate(cmdArgs, "permit_expiry_report", 1), validate(cmdArgs, "permit_expiry_report", 2):
+		// Lists the permits expiring within a number of days, 7 by default
+		days := 7
+		if len(cmdArgs) == 2 {
+			var err error
+			days, err = strconv.Atoi(cmdArgs[1])
 			if err != nil {
-				panic(err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+		}
+		permits, err := lot.ExpiringPermits(days)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if len(permits) == 0 {
+			fmt.Fprintf(s.out, "No permits expire within %v days\n", days)
+		}
+		now := lot.Clock().Now()
+		for _, permit := range permits {
+			var status string
+			switch daysLeft := permit.DaysLeft(now); {
+			case permit.IsExpiredAt(now):
+				status = "expired"
+			case daysLeft == 0:
+				status = "expires today"
+			default:
+				status = fmt.Sprintf("expires in %v days", daysLeft)
 			}
+			fmt.Fprintf(s.out, "Registration number: %v, valid until: %v, %v\n",
+				permit.RegistrationNumber(), formatDate(permit.ValidUntil()), status)
+		}
 
-		case validate(cmdArgs, "slot_number_for_registration_number", 2):
-			slotNumber, err := parkinglot.getVehicleByRegistrationNumber(cmdArgs[1])
+	case validate(cmdArgs, "reserve", 5), validate(cmdArgs, "reserve", 6):
+		// The window starts at a date and a time of day and lasts for a
+		// duration, such as 2026-10-17 10:00:00 2h. The vehicle type is
+		// optional and defaults to a car.
+		start, err := parseTime(cmdArgs[2] + " " + cmdArgs[3])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		duration, err := time.ParseDuration(cmdArgs[4])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		vehicleType := parkinglot.Car
+		if len(cmdArgs) == 6 {
+			vehicleType, err = parkinglot.ParseVehicleType(cmdArgs[5])
 			if err != nil {
-				fmt.Fprintln(runOpts.Stdout, err.Error())
+				fmt.Fprintln(s.out, errorMessage(err))
 				break
 			}
-			fmt.Fprintln(runOpts.Stdout, slotNumber)
+		}
+		slotNumber := 0
+		if value, ok := flags["slot"]; ok {
+			slotNumber, err = strconv.Atoi(value)
+			if err != nil {
+				fmt.Fprintln(s.out, errorMessage(err))
+				break
+			}
+			if slotNumber <= 0 {
+				fmt.Fprintln(s.out, "Invalid slot number")
+				break
+			}
+		}
+		reservation, err := lot.Reserve(cmdArgs[1], vehicleType, start, duration, slotNumber)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Reserved slot number: %v, reservation number: %v\n",
+			slotLabel(lot, lot.Slot(reservation.SlotNumber())), reservation.ReservationNumber())
 
-		case validate(cmdArgs, "exit", 1):
-			exit = true
+	case validate(cmdArgs, "cancel_reservation", 2):
+		reservationNumber, err := strconv.Atoi(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if err := lot.CancelReservation(reservationNumber); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Reservation number %v is cancelled\n", reservationNumber)
 
-		default:
-			fmt.Fprintln(runOpts.Stdout, "Unknown input command")
+	case validate(cmdArgs, "reservations", 1):
+		reservations, err := lot.Reservations()
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if len(reservations) == 0 {
+			fmt.Fprintln(s.out, "No reservations")
+		}
+		for _, reservation := range reservations {
+			fmt.Fprintf(s.out, "Reservation number: %v, registration number: %v, slot number: %v, from: %v, until: %v, status: %v\n",
+				reservation.ReservationNumber(), reservation.RegistrationNumber(),
+				slotLabel(lot, lot.Slot(reservation.SlotNumber())),
+				formatTime(reservation.Start()), formatTime(reservation.End()), reservation.Status())
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+	case validate(cmdArgs, "overrides", 1):
+		for _, override := range lot.Overrides() {
+			ticket := override.Ticket()
+			fmt.Fprintf(s.out, "Time: %v, ticket number: %v, registration number: %v, slot number: %v, reason: %v\n",
+				formatTime(override.Time()), ticket.TicketNumber(), ticket.Vehicle().RegistrationNumber(),
+				slotsLabel(lot, ticket.Slots()), override.Reason())
+		}
+
+	case validate(cmdArgs, "status", 1):
+		slots := lot.Status()
+		multiStorey := lot.FloorCount() > 1
+		var w = tabwriter.NewWriter(s.out, 0, 0, 4, ' ', 0)
+		if multiStorey {
+			fmt.Fprintln(w, "Slot No.\tFloor\tRegistration No\tColour")
+		} else {
+			fmt.Fprintln(w, "Slot No.\tRegistration No\tColour")
+		}
+		for _, slot := range slots {
+			vehicle := slot.Vehicle()
+			var s string
+			if multiStorey {
+				s = fmt.Sprintf("%v\t%v\t%s\t%s", spanLabel(vehicle.Slots()), slot.FloorNumber(), vehicle.RegistrationNumber(), vehicle.Color())
+			} else {
+				s = fmt.Sprintf("%v\t%s\t%s", spanLabel(vehicle.Slots()), vehicle.RegistrationNumber(), vehicle.Color())
+			}
+			fmt.Fprintln(w, s)
+		}
+		w.Flush()
+
+	case validate(cmdArgs, "registration_numbers_for_cars_with_colour", 2):
+		_, regisNumbers, err := lot.VehiclesByColor(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		err = printer.Fprintf(s.out, regisNumbers)
+		if err != nil {
+			panic(err.Error())
+		}
+
+	case validate(cmdArgs, "slot_numbers_for_cars_with_colour", 2):
+		slotNumbers, _, err := lot.VehiclesByColor(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		err = printer.Fprintf(s.out, slotLabels(lot, slotNumbers))
+		if err != nil {
+			panic(err.Error())
+		}
+
+	case validate(cmdArgs, "slot_number_for_registration_number", 2):
+		slotNumber, err := lot.SlotNumberForRegistrationNumber(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintln(s.out, slotLabel(lot, lot.Slot(slotNumber)))
+
+	case validate(cmdArgs, "slot_numbers_with_attribute", 2):
+		attributes, err := parkinglot.ParseAttributes(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		slotNumbers, err := lot.SlotsWithAttributes(attributes)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		err = printer.Fprintf(s.out, slotLabels(lot, slotNumbers))
+		if err != nil {
+			panic(err.Error())
+		}
+
+	case validate(cmdArgs, "free_slot_count_with_attribute", 2):
+		attributes, err := parkinglot.ParseAttributes(cmdArgs[1])
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		count, err := lot.FreeSlotCount(attributes)
+		if err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintln(s.out, count)
+
+	case validate(cmdArgs, "save", 2):
+		if err := saveSnapshotFile(lot, cmdArgs[1]); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintf(s.out, "Saved the parking lot to %v\n", cmdArgs[1])
+
+	case validate(cmdArgs, "load", 2):
+		if err := loadSnapshotFile(lot, cmdArgs[1]); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		if s.journal != nil {
+			// The journal cannot point at the snapshot file, which may
+			// change, so it starts over from the loaded parking lot
+			if err := s.journal.compact(lot, lot.Clock().Now()); err != nil {
+				log.Fatal(err)
+			}
+		}
+		fmt.Fprintf(s.out, "Loaded a parking lot with %v slots from %v\n", lot.Capacity(), cmdArgs[1])
+
+	case validate(cmdArgs, "compact_journal", 1):
+		if s.journal == nil {
+			fmt.Fprintln(s.out, "Journal is not enabled")
+			break
+		}
+		if err := s.journal.compact(lot, lot.Clock().Now()); err != nil {
+			fmt.Fprintln(s.out, errorMessage(err))
+			break
+		}
+		fmt.Fprintln(s.out, "Compacted the journal")
+
+	case validate(cmdArgs, "exit", 1):
+		return true
+
+	default:
+		fmt.Fprintln(s.out, "Unknown input command")
 	}
+	return false
+}
+
+// Describes an entry gate next to a slot
+type gateLayout struct {
+	name       string
+	slotNumber int
 }
 
 func parse(input string) []string {
@@ -166,3 +802,275 @@ func validate(cmdArgs []string, expectedCmd string, expectedLength int) bool {
 	}
 	return false
 }
+
+// Split command arguments into positional arguments and "--name value" flags
+func parseFlags(cmdArgs []string) ([]string, map[string]string) {
+	var positional []string
+	flags := make(map[string]string)
+
+	for i := 0; i < len(cmdArgs); i++ {
+		arg := cmdArgs[i]
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+		name := strings.TrimPrefix(arg, "--")
+		if i+1 < len(cmdArgs) && !strings.HasPrefix(cmdArgs[i+1], "--") {
+			flags[name] = cmdArgs[i+1]
+			i++
+		} else {
+			flags[name] = ""
+		}
+	}
+
+	if positional == nil {
+		positional = []string{""}
+	}
+	return positional, flags
+}
+
+// Parse a comma separated list of integers, such as "4,4,6"
+func parseIntList(input string) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(input, ",") {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Parse a list of slot sizes with their counts, such as "small:2,large:1"
+func parseSlotSizes(input string) ([]parkinglot.SlotSize, error) {
+	var sizes []parkinglot.SlotSize
+	for _, field := range strings.Split(input, ",") {
+		parts := strings.SplitN(field, ":", 2)
+		size, err := parkinglot.ParseSlotSize(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		count := 1
+		if len(parts) == 2 {
+			count, err = strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i := 0; i < count; i++ {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes, nil
+}
+
+// Assign slot sizes to the floors in slot number order
+func applySlotSizes(layouts []parkinglot.FloorLayout, sizes []parkinglot.SlotSize) error {
+	for i := range layouts {
+		n := layouts[i].Capacity
+		if n > len(sizes) {
+			n = len(sizes)
+		}
+		layouts[i].Sizes, sizes = sizes[:n], sizes[n:]
+	}
+	if len(sizes) > 0 {
+		return errors.New("Number of slot sizes exceeds capacity")
+	}
+	return nil
+}
+
+// Parse slot attributes with the slot numbers that have them, such as
+// "ev:1-2,covered:1-4,accessible:5". Returns the attributes of each slot.
+func parseSlotAttributes(input string, capacity int) ([]parkinglot.Attributes, error) {
+	attributes := make([]parkinglot.Attributes, capacity)
+	for _, field := range strings.Split(input, ",") {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Missing slot numbers for attribute: %v", field)
+		}
+		attribute, err := parkinglot.ParseAttributes(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		bounds := strings.SplitN(parts[1], "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if first <= 0 || last > capacity || first > last {
+			return nil, fmt.Errorf("Invalid slot numbers for attribute: %v", field)
+		}
+		for n := first; n <= last; n++ {
+			attributes[n-1] |= attribute
+		}
+	}
+	return attributes, nil
+}
+
+// Parse gates with the slot number each gate is next to, such as
+// "north:1,south:10"
+func parseGates(input string, capacity int) ([]gateLayout, error) {
+	var gates []gateLayout
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(input, ",") {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Missing slot number for gate: %v", field)
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("Duplicate gate: %v", parts[0])
+		}
+		seen[parts[0]] = true
+		slotNumber, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if slotNumber <= 0 || slotNumber > capacity {
+			return nil, fmt.Errorf("Invalid slot number for gate: %v", field)
+		}
+		gates = append(gates, gateLayout{name: parts[0], slotNumber: slotNumber})
+	}
+	return gates, nil
+}
+
+// Assign slot attributes to the floors in slot number order
+func applySlotAttributes(layouts []parkinglot.FloorLayout, attributes []parkinglot.Attributes) {
+	for i := range layouts {
+		n := layouts[i].Capacity
+		if n > len(attributes) {
+			n = len(attributes)
+		}
+		if n > 0 {
+			layouts[i].Attributes, attributes = attributes[:n], attributes[n:]
+		}
+	}
+}
+
+// Returns the total capacity of the floors, ignoring invalid capacities
+func sumCapacity(layouts []parkinglot.FloorLayout) int {
+	capacity := 0
+	for _, layout := range layouts {
+		if layout.Capacity > 0 {
+			capacity += layout.Capacity
+		}
+	}
+	return capacity
+}
+
+// Format a slot number for display, as the span of slots for a vehicle parked
+// in several of them. The floor is only shown for parking lots with more than
+// one floor.
+func slotLabel(pl *parkinglot.ParkingLot, slot *parkinglot.Slot) string {
+	if vehicle := slot.Vehicle(); vehicle != nil {
+		return slotsLabel(pl, vehicle.Slots())
+	}
+	return slotsLabel(pl, []*parkinglot.Slot{slot})
+}
+
+// Format a span of adjacent slots for display, along with the floor for
+// parking lots with more than one floor
+func slotsLabel(pl *parkinglot.ParkingLot, slots []*parkinglot.Slot) string {
+	if pl.FloorCount() > 1 {
+		return fmt.Sprintf("%v (floor %v)", spanLabel(slots), slots[0].FloorNumber())
+	}
+	return spanLabel(slots)
+}
+
+// Print the slots freed when a vehicle leaves
+func printFreedSlots(w io.Writer, ticket *parkinglot.Ticket) {
+	slots := ticket.Slots()
+	if len(slots) > 1 {
+		fmt.Fprintf(w, "Slot numbers %v are free\n", spanLabel(slots))
+	} else {
+		fmt.Fprintf(w, "Slot number %v is free\n", slots[0].SlotNumber())
+	}
+}
+
+// Print the waiting vehicles given the slots freed when a vehicle left
+func printAssigned(w io.Writer, pl *parkinglot.ParkingLot, ticket *parkinglot.Ticket) {
+	for _, assigned := range ticket.Assigned() {
+		fmt.Fprintf(w, "Allocated slot number: %v to %v\n", slotsLabel(pl, assigned.Slots()), assigned.Vehicle().RegistrationNumber())
+		fmt.Fprintf(w, "Ticket number: %v, entry time: %v\n", assigned.TicketNumber(), formatTime(assigned.EntryTime()))
+	}
+}
+
+// Print an event from the history
+func printEvent(w io.Writer, pl *parkinglot.ParkingLot, event *parkinglot.Event) {
+	var slots []*parkinglot.Slot
+	for _, slotNumber := range event.SlotNumbers() {
+		slots = append(slots, pl.Slot(slotNumber))
+	}
+	fmt.Fprintf(w, "Time: %v, event: %v, ticket number: %v, registration number: %v, colour: %v, slot number: %v",
+		formatTime(event.Time()), event.Type(), event.TicketNumber(), event.RegistrationNumber(),
+		event.Color(), slotsLabel(pl, slots))
+	if event.Operator() != "" {
+		fmt.Fprintf(w, ", operator: %v", event.Operator())
+	}
+	fmt.Fprintln(w)
+}
+
+// Print the fee charged when a vehicle leaves, if the parking lot has a tariff
+func printFee(w io.Writer, pl *parkinglot.ParkingLot, ticket *parkinglot.Ticket) {
+	if pl.Tariff() != nil {
+		fmt.Fprintf(w, "Parking fee: %v\n", parkinglot.FormatFee(ticket.Fee()))
+	}
+}
+
+// Layout of points in time for display and input
+const timeLayout = "2006-01-02 15:04:05"
+
+// Format a point in time for display
+func formatTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+// Parse a point in time in the local time zone, such as "2026-10-17 09:00:00"
+func parseTime(input string) (time.Time, error) {
+	return time.ParseInLocation(timeLayout, input, time.Local)
+}
+
+const dateLayout = "2006-01-02"
+
+// Format a day for display
+func formatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// Parse a day in the local time zone, such as "2026-10-17"
+func parseDate(input string) (time.Time, error) {
+	return time.ParseInLocation(dateLayout, input, time.Local)
+}
+
+// Format where a permit holder parks for display, such as "slot number: 5"
+func permitLabel(pl *parkinglot.ParkingLot, permit *parkinglot.Permit) string {
+	if permit.IsFloating() {
+		return "the floating pool"
+	}
+	return "slot number: " + slotLabel(pl, pl.Slot(permit.SlotNumber()))
+}
+
+// Format a span of adjacent slots for display, such as "7-9"
+func spanLabel(slots []*parkinglot.Slot) string {
+	first := slots[0].SlotNumber()
+	last := slots[len(slots)-1].SlotNumber()
+	if first == last {
+		return strconv.Itoa(first)
+	}
+	return fmt.Sprintf("%v-%v", first, last)
+}
+
+func slotLabels(pl *parkinglot.ParkingLot, slotNumbers []int) []string {
+	var labels []string
+	for _, slotNumber := range slotNumbers {
+		labels = append(labels, slotLabel(pl, pl.Slot(slotNumber)))
+	}
+	return labels
+}
@@ -2,15 +2,25 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
 )
 
+// Fixed time the tests run at
+var testTime = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
 func TestCommand(t *testing.T) {
 	runOpts := &RunOptions{
 		Stdin:  os.Stdin,
 		Stdout: os.Stdout,
+		Clock:  parkinglot.NewFakeClock(testTime),
 	}
 
 	// Wire up interactive inputs redirection
@@ -28,11 +38,17 @@ func TestCommand(t *testing.T) {
 	// Expected CLI output
 	out := `Created a parking lot with 6 slots
 Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
 Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
 Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 09:00:00
 Allocated slot number: 4
+Ticket number: 4, entry time: 2026-10-17 09:00:00
 Allocated slot number: 5
+Ticket number: 5, entry time: 2026-10-17 09:00:00
 Allocated slot number: 6
+Ticket number: 6, entry time: 2026-10-17 09:00:00
 Slot number 4 is free
 Slot No.    Registration No    Colour
 1           KA-01-HH-1234      White
@@ -41,6 +57,7 @@ Slot No.    Registration No    Colour
 5           KA-01-HH-2701      Blue
 6           KA-01-HH-3141      Black
 Allocated slot number: 4
+Ticket number: 7, entry time: 2026-10-17 09:00:00
 Sorry, parking lot is full
 KA-01-HH-1234, KA-01-HH-9999, KA-01-P-333
 1, 2, 4
@@ -78,3 +95,682 @@ Unknown input command
 		gotBuf.Reset()
 	}
 }
+
+// Run the commands in the input file and return the CLI output
+func runInputFile(t *testing.T, path string, flags ...string) string {
+	t.Helper()
+	var gotBuf bytes.Buffer
+	args := append(append([]string{"cmd"}, flags...), path)
+	RunCustom(args, &RunOptions{Stdout: &gotBuf, Clock: parkinglot.NewFakeClock(testTime)})
+	return gotBuf.String()
+}
+
+func TestMultiStoreyCommand(t *testing.T) {
+	want := `Created a parking lot with 5 slots on 2 floors
+Allocated slot number: 3 (floor 2)
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 4 (floor 2)
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Allocated slot number: 5 (floor 2)
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Allocated slot number: 1 (floor 1)
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Slot number 4 is free
+Slot No.    Floor    Registration No    Colour
+1           1        KA-01-HH-7777      Red
+3           2        KA-01-HH-1234      White
+5           2        KA-01-BB-0001      Black
+Allocated slot number: 4 (floor 2)
+Ticket number: 5, entry time: 2026-10-17 09:00:00
+KA-01-HH-1234, KA-01-P-333
+3 (floor 2), 4 (floor 2)
+1 (floor 1)
+`
+	if got := runInputFile(t, "../test/input_multi_storey.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestVehicleTypeCommand(t *testing.T) {
+	want := `Created a parking lot with 6 slots on 2 floors
+Allocated slot number: 1 (floor 1)
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2 (floor 1)
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Allocated slot number: 4-5 (floor 2)
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Allocated slot number: 6 (floor 2)
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Sorry, parking lot is full
+Allocated slot number: 3 (floor 1)
+Ticket number: 5, entry time: 2026-10-17 09:00:00
+Unknown vehicle type: truck
+Slot No.    Floor    Registration No    Colour
+1           1        KA-01-HH-1234      White
+2           1        KA-01-HH-9999      White
+3           1        KA-01-HH-3141      Black
+4-5         2        KA-01-BB-0001      Black
+6           2        KA-01-HH-7777      Red
+Slot numbers 4-5 are free
+3 (floor 1)
+Sorry, parking lot is full
+Allocated slot number: 4 (floor 2)
+Ticket number: 6, entry time: 2026-10-17 09:00:00
+`
+	if got := runInputFile(t, "../test/input_vehicle_types.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestSlotAttributeCommand(t *testing.T) {
+	want := `Created a parking lot with 6 slots
+Allocated slot number: 5
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 1
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Sorry, parking lot is full
+Unknown slot attribute: valet
+2, 3, 4
+2
+0
+Allocated slot number: 6
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Allocated slot number: 3
+Ticket number: 5, entry time: 2026-10-17 09:00:00
+Slot No.    Registration No    Colour
+1           KA-01-HH-9999      White
+2           KA-01-BB-0001      Black
+3           KA-01-HH-4321      Green
+5           KA-01-HH-1234      White
+6           KA-01-HH-3141      Black
+`
+	if got := runInputFile(t, "../test/input_attributes.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestTicketCommand(t *testing.T) {
+	want := `Created a parking lot with 3 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Slot number 1 is free
+Exit time: 2026-10-17 09:00:00, duration: 0s
+Ticket is already closed
+Slot number 2 is free
+Ticket number: 1, registration number: KA-01-HH-1234, slot number: 1, entry time: 2026-10-17 09:00:00, exit time: 2026-10-17 09:00:00, duration: 0s
+Ticket number: 2, registration number: KA-01-HH-9999, slot number: 2, entry time: 2026-10-17 09:00:00, exit time: 2026-10-17 09:00:00, duration: 0s
+Allocated slot number: 1
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Ticket number: 3, registration number: KA-01-HH-7777, slot number: 1, entry time: 2026-10-17 09:00:00, parked
+Ticket not found
+`
+	if got := runInputFile(t, "../test/input_tickets.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestTariffCommand(t *testing.T) {
+	want := `Created a parking lot with 3 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Slot number 1 is free
+Parking fee: 0.00
+Slot number 2 is free
+Exit time: 2026-10-17 09:00:00, duration: 0s
+Parking fee: 0.00
+Ticket number: 1, registration number: KA-01-HH-1234, slot number: 1, entry time: 2026-10-17 09:00:00, exit time: 2026-10-17 09:00:00, duration: 0s, fee: 0.00
+`
+	if got := runInputFile(t, "../test/input_tariff.txt", "-tariff", "../test/tariff.json"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestAllocatorCommand(t *testing.T) {
+	want := `Unknown allocator: cheapest, use one of fill_from_back, nearest_entry, nearest_exit, random, round_robin, wear_levelling
+Created a parking lot with 4 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Slot number 1 is free
+Exit time: 2026-10-17 09:00:00, duration: 0s
+Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Slot No.    Registration No    Colour
+2           KA-01-HH-9999      White
+3           KA-01-BB-0001      Black
+`
+	if got := runInputFile(t, "../test/input_allocator.txt", "-allocator", "round_robin"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestGateCommand(t *testing.T) {
+	want := `Duplicate gate: north
+Created a parking lot with 6 slots on 2 floors
+Allocated slot number: 6 (floor 2)
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 1 (floor 1)
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Allocated slot number: 5 (floor 2)
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2 (floor 1)
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Gate not found
+Slot number 6 is free
+Allocated slot number: 6 (floor 2)
+Ticket number: 5, entry time: 2026-10-17 09:00:00
+Slot No.    Floor    Registration No    Colour
+1           1        KA-01-HH-9999      White
+2           1        KA-01-HH-7777      Red
+5           2        KA-01-BB-0001      Black
+6           2        KA-01-HH-3141      Black
+`
+	if got := runInputFile(t, "../test/input_gates.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestOverrideCommand(t *testing.T) {
+	want := `Created a parking lot with 4 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Vehicle with registration number KA-01-HH-1234 is already parked
+1
+Override needs a reason
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Time: 2026-10-17 09:00:00, ticket number: 2, registration number: KA-01-HH-1234, slot number: 2, reason: cloned_plate
+Slot number 1 is free
+Vehicle with registration number KA-01-HH-1234 is already parked
+`
+	if got := runInputFile(t, "../test/input_override.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestPlateFormatCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags []string
+		want  string
+	}{
+		{
+			name: "Generic plates",
+			want: `Created a parking lot with 3 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Vehicle with registration number KA-01-HH-1234 is already parked
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+1
+Not found
+KA-1-P-333
+`,
+		},
+		{
+			name:  "Indian plates",
+			flags: []string{"-plate_format", "indian"},
+			want: `Created a parking lot with 3 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Vehicle with registration number KA-01-HH-1234 is already parked
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Invalid Indian registration number: KA-01-HH-XYZ
+1
+2
+KA-01-P-0333
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runInputFile(t, "../test/input_plates.txt", tt.flags...); got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColourCommand(t *testing.T) {
+	want := `Created a parking lot with 4 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Unknown colour: Whte, did you mean White?
+Unknown colour: Teal
+Allocated slot number: 4
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+KA-01-HH-9999, KA-01-BB-0001
+2, 3
+Unknown colour: Blak, did you mean Black?
+Slot No.    Registration No    Colour
+1           KA-01-HH-1234      White
+2           KA-01-HH-9999      Grey
+3           KA-01-BB-0001      Grey
+4           KA-01-HH-3141      White
+`
+	if got := runInputFile(t, "../test/input_colours.txt", "-colours", "../test/colours.json", "-strict_colours"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestAdvanceTimeCommand(t *testing.T) {
+	want := `Created a parking lot with 2 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 08:00:00
+Time: 2026-10-17 10:30:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 10:30:00
+Time: 2026-10-17 11:15:00
+Slot number 1 is free
+Parking fee: 9.00
+Slot number 2 is free
+Exit time: 2026-10-17 11:15:00, duration: 45m0s
+Parking fee: 3.00
+Ticket number: 1, registration number: KA-01-HH-1234, slot number: 1, entry time: 2026-10-17 08:00:00, exit time: 2026-10-17 11:15:00, duration: 3h15m0s, fee: 9.00
+Time cannot go backwards
+time: invalid duration "soon"
+`
+	got := runInputFile(t, "../test/input_clock.txt", "-tariff", "../test/tariff.json", "-fake_time", "2026-10-17 08:00:00")
+	if got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	want := `Created a parking lot with 2 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Time: 2026-10-17 10:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 10:00:00
+Time: 2026-10-17 10:30:00
+Slot number 1 is free
+Time: 2026-10-17 10:45:00
+Allocated slot number: 1
+Ticket number: 3, entry time: 2026-10-17 10:45:00
+Time: 2026-10-17 09:00:00, event: park, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: alice
+Time: 2026-10-17 10:30:00, event: leave, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: bob
+Time: 2026-10-17 09:00:00, event: park, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: alice
+Time: 2026-10-17 10:30:00, event: leave, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: bob
+Time: 2026-10-17 10:45:00, event: park, ticket number: 3, registration number: KA-01-BB-0001, colour: Red, slot number: 1, operator: bob
+Time: 2026-10-17 10:00:00, event: park, ticket number: 2, registration number: KA-01-HH-9999, colour: Black, slot number: 2, operator: bob
+Time: 2026-10-17 09:00:00, event: park, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: alice
+Not found
+Time: 2026-10-17 10:45:00, event: park, ticket number: 3, registration number: KA-01-BB-0001, colour: Red, slot number: 1, operator: bob
+Not found
+Invalid slot number
+Not found
+`
+	if got := runInputFile(t, "../test/input_history.txt", "-operator", "alice"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestWaitlistCommand(t *testing.T) {
+	want := `Created a parking lot with 2 slots
+Added permit for KA-01-HH-2701 in the floating pool
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Sorry, parking lot is full, waitlist position: 1
+Sorry, parking lot is full, waitlist position: 2
+Sorry, parking lot is full, waitlist position: 1
+Vehicle with registration number KA-01-HH-7777 is already waiting
+Position: 1, registration number: KA-01-HH-2701, colour: Blue, waiting since: 2026-10-17 09:00:00, permit holder
+Position: 2, registration number: KA-01-BB-0001, colour: Black, waiting since: 2026-10-17 09:00:00
+Position: 3, registration number: KA-01-HH-7777, colour: Red, waiting since: 2026-10-17 09:00:00
+Registration number KA-01-BB-0001 left the waitlist
+Not found
+Slot number 1 is free
+Allocated slot number: 1 to KA-01-HH-2701
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Time: 2026-10-17 09:10:00
+Slot number 2 is free
+Exit time: 2026-10-17 09:10:00, duration: 10m0s
+Allocated slot number: 2 to KA-01-HH-7777
+Ticket number: 4, entry time: 2026-10-17 09:10:00
+Waitlist is empty
+Sorry, parking lot is full, waitlist position: 1
+`
+	if got := runInputFile(t, "../test/input_waitlist.txt", "-waitlist"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestPermitCommand(t *testing.T) {
+	want := `Created a parking lot with 4 slots
+Added permit for KA-01-HH-1234 in slot number: 1
+Added permit for KA-01-HH-9999 in the floating pool
+Registration number KA-01-HH-9999 already has a permit
+Permit must end on or after the day it starts
+Slot is already dedicated to a permit
+Reserved 1 slots for floating permits
+Allocated slot number: 2
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 3
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Sorry, parking lot is full
+Allocated slot number: 4
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Allocated slot number: 1
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Slot number 1 is free
+Sorry, parking lot is full
+Registration number: KA-01-HH-1234, valid from: 2026-10-01, valid until: 2026-10-31, slot number: 1
+Registration number: KA-01-HH-9999, valid from: 2026-10-01, valid until: 2026-10-20, the floating pool
+Registration number: KA-01-HH-9999, valid until: 2026-10-20, expires in 3 days
+Time: 2026-10-21 09:00:00
+Registration number: KA-01-HH-9999, valid until: 2026-10-20, expired
+Registration number: KA-01-HH-1234, valid until: 2026-10-31, expires in 10 days
+Revoked permit for KA-01-HH-1234
+Not found
+Allocated slot number: 1
+Ticket number: 5, entry time: 2026-10-21 09:00:00
+`
+	if got := runInputFile(t, "../test/input_permits.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestReservationCommand(t *testing.T) {
+	want := `Created a parking lot with 3 slots
+Reserved slot number: 1, reservation number: 1
+Slot is already reserved by reservation number 1
+Reserved slot number: 1, reservation number: 2
+Registration number KA-01-HH-1234 already has reservation number 1 at that time
+Reservation must not start in the past
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Time: 2026-10-17 10:00:00
+Reservation number: 1, registration number: KA-01-HH-1234, slot number: 1, from: 2026-10-17 10:00:00, until: 2026-10-17 12:00:00, status: pending
+Reservation number: 2, registration number: KA-01-HH-9999, slot number: 1, from: 2026-10-17 12:00:00, until: 2026-10-17 13:00:00, status: pending
+Slot number 1 is free
+Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 10:00:00
+Sorry, parking lot is full
+Allocated slot number: 1
+Ticket number: 4, entry time: 2026-10-17 10:00:00
+Slot number 1 is free
+Time: 2026-10-17 12:00:00
+Sorry, parking lot is full
+Time: 2026-10-17 12:15:00
+Allocated slot number: 1
+Ticket number: 5, entry time: 2026-10-17 12:15:00
+Reserved slot number: 1, reservation number: 3
+Reservation number 3 is cancelled
+Reservation is already cancelled
+Reservation number: 1, registration number: KA-01-HH-1234, slot number: 1, from: 2026-10-17 10:00:00, until: 2026-10-17 12:00:00, status: fulfilled
+Reservation number: 2, registration number: KA-01-HH-9999, slot number: 1, from: 2026-10-17 12:00:00, until: 2026-10-17 13:00:00, status: no-show
+Reservation number: 3, registration number: KA-01-HH-2701, slot number: 1, from: 2026-10-17 13:00:00, until: 2026-10-17 14:00:00, status: cancelled
+`
+	if got := runInputFile(t, "../test/input_reservations.txt"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestSnapshotCommand(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state.json")
+	saved := filepath.Join(dir, "saved.json")
+	scripts := []struct {
+		name   string
+		script string
+		flags  []string
+		want   string
+	}{
+		{
+			name: "Start without a state file",
+			script: `create_parking_lot 4
+park KA-01-HH-1234 White
+park KA-01-HH-9999 White
+leave 1
+`,
+			flags: []string{"-allocator", "round_robin", "-state", state},
+			want: `Created a parking lot with 4 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Slot number 1 is free
+`,
+		},
+		{
+			name: "Restart from the state file",
+			script: `park KA-01-BB-0001 Black
+save ` + saved + `
+load ` + saved + `
+`,
+			flags: []string{"-state", state},
+			want: `Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Saved the parking lot to ` + saved + `
+Parking lot already created
+`,
+		},
+		{
+			name: "Load a saved snapshot",
+			script: `load ` + filepath.Join(dir, "missing.json") + `
+load ` + saved + `
+park KA-01-HH-7777 Red
+status
+`,
+			want: `open ` + filepath.Join(dir, "missing.json") + `: no such file or directory
+Loaded a parking lot with 4 slots from ` + saved + `
+Allocated slot number: 4
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Slot No.    Registration No    Colour
+2           KA-01-HH-9999      White
+3           KA-01-BB-0001      Black
+4           KA-01-HH-7777      Red
+`,
+		},
+	}
+
+	for i, tt := range scripts {
+		path := filepath.Join(dir, fmt.Sprintf("input_%d.txt", i))
+		if err := os.WriteFile(path, []byte(tt.script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := runInputFile(t, path, tt.flags...); got != tt.want {
+			t.Errorf("%v: got = %v, want = %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJournalCommand(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal.log")
+	scripts := []struct {
+		name   string
+		script string
+		tail   string // Written to the end of the journal after the run
+		want   string
+	}{
+		{
+			name: "Start without a journal",
+			script: `create_parking_lot 4 --allocator round_robin
+park KA-01-HH-1234 White
+park KA-01-HH-9999 White
+leave 1
+`,
+			want: `Created a parking lot with 4 slots
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Slot number 1 is free
+`,
+		},
+		{
+			name: "Replay the journal and compact it",
+			script: `park KA-01-BB-0001 Black
+compact_journal
+`,
+			tail: `0badf00d {"seq":7,"command":"park KA-01-HH-77`,
+			want: `Allocated slot number: 3
+Ticket number: 3, entry time: 2026-10-17 09:00:00
+Compacted the journal
+`,
+		},
+		{
+			name: "Drop a torn record and rebuild from the compacted journal",
+			script: `park KA-01-HH-7777 Red
+status
+`,
+			want: `Allocated slot number: 4
+Ticket number: 4, entry time: 2026-10-17 09:00:00
+Slot No.    Registration No    Colour
+2           KA-01-HH-9999      White
+3           KA-01-BB-0001      Black
+4           KA-01-HH-7777      Red
+`,
+		},
+		{
+			name:   "Keep the records written after a torn one",
+			script: "status\n",
+			want: `Slot No.    Registration No    Colour
+2           KA-01-HH-9999      White
+3           KA-01-BB-0001      Black
+4           KA-01-HH-7777      Red
+`,
+		},
+	}
+
+	for i, tt := range scripts {
+		path := filepath.Join(dir, fmt.Sprintf("input_%d.txt", i))
+		if err := os.WriteFile(path, []byte(tt.script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := runInputFile(t, path, "-journal", journalPath); got != tt.want {
+			t.Errorf("%v: got = %v, want = %v", tt.name, got, tt.want)
+		}
+		if tt.tail != "" {
+			f, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tt.tail)
+			f.Close()
+		}
+	}
+}
+
+// A parking lot rebuilt from its journal is the one the commands were run on,
+// whichever allocator handed out its slots
+func TestJournalReplay(t *testing.T) {
+	for _, allocator := range []string{"nearest_entry", "nearest_exit", "fill_from_back", "round_robin", "wear_levelling", "random"} {
+		t.Run(allocator, func(t *testing.T) {
+			dir := t.TempDir()
+			journalPath := filepath.Join(dir, "journal.log")
+			scripts := []string{
+				`create_parking_lot 4,4 --allocator ` + allocator + `
+park KA-01-HH-0001 White
+park KA-01-HH-0002 White
+park KA-01-HH-0003 White
+park KA-01-HH-0004 White
+park KA-01-HH-0005 White
+leave_ticket 2
+leave_ticket 4
+park KA-01-HH-0006 Red
+leave_ticket 1
+park KA-01-HH-0007 Red
+park KA-01-HH-0008 Red
+status
+`,
+				"status\ncompact_journal\n",
+				"status\n",
+			}
+
+			var status string
+			for i, script := range scripts {
+				path := filepath.Join(dir, fmt.Sprintf("input_%d.txt", i))
+				if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				got := runInputFile(t, path, "-journal", journalPath)
+				if i == 0 {
+					status = got[strings.Index(got, "Slot No."):]
+					continue
+				}
+				if !strings.HasPrefix(got, status) {
+					t.Errorf("restart %v: got = %v, want = %v", i, got, status)
+				}
+			}
+		})
+	}
+}
+
+// The operator on duty and a fake clock carry on from where the journal left
+// them, whether it was compacted or not
+func TestJournalOperatorAndClock(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, "journal.log")
+	scripts := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			name: "Start without a journal",
+			script: `create_parking_lot 2
+operator bob
+advance_time 1h
+park KA-01-HH-1234 White
+`,
+			want: `Created a parking lot with 2 slots
+Time: 2026-10-17 09:00:00
+Allocated slot number: 1
+Ticket number: 1, entry time: 2026-10-17 09:00:00
+`,
+		},
+		{
+			name: "Replay the operator command",
+			script: `park KA-01-HH-9999 White
+compact_journal
+`,
+			want: `Allocated slot number: 2
+Ticket number: 2, entry time: 2026-10-17 09:00:00
+Compacted the journal
+`,
+		},
+		{
+			name: "Rebuild from the compacted journal",
+			script: `leave 1
+history_for_registration_number KA-01-HH-1234
+`,
+			want: `Slot number 1 is free
+Time: 2026-10-17 09:00:00, event: park, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: bob
+Time: 2026-10-17 09:00:00, event: leave, ticket number: 1, registration number: KA-01-HH-1234, colour: White, slot number: 1, operator: bob
+`,
+		},
+	}
+
+	for i, tt := range scripts {
+		path := filepath.Join(dir, fmt.Sprintf("input_%d.txt", i))
+		if err := os.WriteFile(path, []byte(tt.script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got := runInputFile(t, path, "-journal", journalPath, "-operator", "alice", "-fake_time", "2026-10-17 08:00:00")
+		if got != tt.want {
+			t.Errorf("%v: got = %v, want = %v", tt.name, got, tt.want)
+		}
+	}
+}
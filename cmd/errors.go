@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
+)
+
+// Messages shown for the errors of a parking lot that need no details, in the
+// order they are checked
+var errorMessages = []struct {
+	target  error
+	message string
+}{
+	{parkinglot.ErrNotCreated, "Parking lot is not created"},
+	{parkinglot.ErrAlreadyCreated, "Parking lot already created"},
+	{parkinglot.ErrNoFloors, "Parking lot must have at least one floor"},
+	{parkinglot.ErrInvalidCapacity, "Floor capacity must be greater than zero"},
+	{parkinglot.ErrTooManySizes, "Number of slot sizes exceeds floor capacity"},
+	{parkinglot.ErrTooManyAttributes, "Number of slot attributes exceeds floor capacity"},
+	{parkinglot.ErrGateExists, "Gate already exists"},
+	{parkinglot.ErrGateNotFound, "Gate not found"},
+	{parkinglot.ErrGateDistances, "Number of gate distances does not match number of slots"},
+	{parkinglot.ErrParkingLotFull, "Sorry, parking lot is full"},
+	{parkinglot.ErrNoReason, "Override needs a reason"},
+	{parkinglot.ErrNoWaitlist, "Parking lot has no waitlist"},
+	{parkinglot.ErrNotFound, "Not found"},
+	{parkinglot.ErrUnknownVehicleType, "Unknown vehicle type"},
+	{parkinglot.ErrInvalidSlot, "Invalid slot number"},
+	{parkinglot.ErrSlotEmpty, "Vehicle is not found in parking lot"},
+	{parkinglot.ErrSlotOccupied, "Slot is occupied"},
+	{parkinglot.ErrSlotReserved, "Slot is reserved"},
+	{parkinglot.ErrSlotDedicated, "Slot is dedicated to a permit"},
+	{parkinglot.ErrVehicleDoesNotFit, "Vehicle does not fit the slot"},
+	{parkinglot.ErrTicketNotFound, "Ticket not found"},
+	{parkinglot.ErrTicketClosed, "Ticket is already closed"},
+	{parkinglot.ErrInvalidPermitDates, "Permit must end on or after the day it starts"},
+	{parkinglot.ErrNegativePermitPool, "Permit pool size must not be negative"},
+	{parkinglot.ErrPermitPoolTooLarge, "Permit pool is larger than the slots not dedicated to a permit"},
+	{parkinglot.ErrNegativeDays, "Number of days must not be negative"},
+	{parkinglot.ErrReservationSpan, "Only vehicles that fit in one slot can reserve"},
+	{parkinglot.ErrInvalidDuration, "Reservation must last longer than zero"},
+	{parkinglot.ErrStartInPast, "Reservation must not start in the past"},
+	{parkinglot.ErrNoSlotFreeAtTime, "No slot is free at that time"},
+	{parkinglot.ErrReservationNotFound, "Reservation not found"},
+	{parkinglot.ErrSnapshotVersion, "Snapshot version is not supported"},
+	{parkinglot.ErrInvalidSnapshot, "Snapshot is invalid"},
+	{parkinglot.ErrAllocatorNotSaved, "Only parking lots with a built-in allocator can be saved"},
+}
+
+// Get the message to show for an error. Errors of a parking lot are shown in
+// the words of the command line, other errors as they are.
+func errorMessage(err error) string {
+	var vehicleErr *parkinglot.VehicleError
+	var reservationErr *parkinglot.ReservationError
+	var fitErr *parkinglot.FitError
+	var waitingErr *parkinglot.WaitingError
+
+	switch {
+	case errors.As(err, &waitingErr):
+		return fmt.Sprintf("Sorry, parking lot is full, waitlist position: %v", waitingErr.Position)
+	case errors.As(err, &fitErr):
+		if fitErr.Needs != 0 {
+			return fmt.Sprintf("Sorry, parking lot has no %v slot for a %v", fitErr.Needs, fitErr.VehicleType)
+		}
+		return fmt.Sprintf("Sorry, parking lot has no slot for a %v", fitErr.VehicleType)
+	case errors.Is(err, parkinglot.ErrAlreadyParked) && errors.As(err, &vehicleErr):
+		return fmt.Sprintf("Vehicle with registration number %v is already parked", vehicleErr.RegistrationNumber)
+	case errors.Is(err, parkinglot.ErrAlreadyWaiting) && errors.As(err, &vehicleErr):
+		return fmt.Sprintf("Vehicle with registration number %v is already waiting", vehicleErr.RegistrationNumber)
+	case errors.Is(err, parkinglot.ErrPermitExists) && errors.As(err, &vehicleErr):
+		return fmt.Sprintf("Registration number %v already has a permit", vehicleErr.RegistrationNumber)
+	case errors.Is(err, parkinglot.ErrReservationOverlaps) && errors.As(err, &vehicleErr) && errors.As(err, &reservationErr):
+		return fmt.Sprintf("Registration number %v already has reservation number %v at that time",
+			vehicleErr.RegistrationNumber, reservationErr.ReservationNumber)
+	case errors.Is(err, parkinglot.ErrSlotReserved) && errors.As(err, &reservationErr):
+		return fmt.Sprintf("Slot is already reserved by reservation number %v", reservationErr.ReservationNumber)
+	case errors.Is(err, parkinglot.ErrReservationInactive) && errors.As(err, &reservationErr):
+		return fmt.Sprintf("Reservation is already %v", reservationErr.Status)
+	}
+
+	for _, m := range errorMessages {
+		if errors.Is(err, m.target) {
+			return m.message
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
+)
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "Sentinel error",
+			err:  parkinglot.ErrNotCreated,
+			want: "Parking lot is not created",
+		},
+		{
+			name: "Sentinel error wrapped with a slot",
+			err:  &parkinglot.SlotError{SlotNumber: 4, Err: parkinglot.ErrInvalidSlot},
+			want: "Invalid slot number",
+		},
+		{
+			name: "Registration number in the message",
+			err:  &parkinglot.VehicleError{RegistrationNumber: "KA-01-HH-1234", Err: parkinglot.ErrAlreadyParked},
+			want: "Vehicle with registration number KA-01-HH-1234 is already parked",
+		},
+		{
+			name: "Reservation in the way",
+			err: &parkinglot.SlotError{
+				SlotNumber: 1,
+				Err:        &parkinglot.ReservationError{ReservationNumber: 2, Err: parkinglot.ErrSlotReserved},
+			},
+			want: "Slot is already reserved by reservation number 2",
+		},
+		{
+			name: "Reservation no longer active",
+			err:  &parkinglot.ReservationError{ReservationNumber: 3, Status: parkinglot.Cancelled, Err: parkinglot.ErrReservationInactive},
+			want: "Reservation is already cancelled",
+		},
+		{
+			name: "No slot fits the vehicle",
+			err:  &parkinglot.FitError{VehicleType: parkinglot.Bus, Needs: parkinglot.EVCharger},
+			want: "Sorry, parking lot has no ev slot for a bus",
+		},
+		{
+			name: "Vehicle put on the waitlist",
+			err:  &parkinglot.WaitingError{Position: 2},
+			want: "Sorry, parking lot is full, waitlist position: 2",
+		},
+		{
+			name: "Error wrapped by the caller",
+			err:  fmt.Errorf("leave: %w", parkinglot.ErrParkingLotFull),
+			want: "Sorry, parking lot is full",
+		},
+		{
+			name: "Error wrapping several sentinels",
+			err:  fmt.Errorf("%w: %w", parkinglot.ErrSlotEmpty, parkinglot.ErrNotCreated),
+			want: "Parking lot is not created",
+		},
+		{
+			name: "Other errors are shown as they are",
+			err:  errors.New("Unknown colour: Fuchsia"),
+			want: "Unknown colour: Fuchsia",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorMessage(tt.err); got != tt.want {
+				t.Errorf("errorMessage() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
+)
+
+var errJournalCorrupt = errors.New("journal is corrupted")
+
+// Commands that change the parking lot, and so are recorded in the journal
+var journaledCommands = map[string]bool{
+	"create_parking_lot": true,
+	"park":               true,
+	"park_override":      true,
+	"leave":              true,
+	"leave_ticket":       true,
+	"operator":           true,
+	"cancel_wait":        true,
+	"add_permit":         true,
+	"revoke_permit":      true,
+	"permit_pool":        true,
+	"reserve":            true,
+	"cancel_reservation": true,
+	"reservations":       true, // Releases the slots of no-shows
+}
+
+// A journal record holds either a snapshot of the parking lot, which is only
+// ever the first record, or a command run on it. The operator on duty is not
+// part of a snapshot, so it is kept beside it.
+type journalRecord struct {
+	Sequence int             `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Snapshot json.RawMessage `json:"snapshot,omitempty"`
+	Operator string          `json:"operator,omitempty"`
+	Command  string          `json:"command,omitempty"`
+}
+
+// An append-only journal of the commands that change the parking lot. Each
+// record is a line with the CRC-32 checksum of the record and the record in
+// JSON, synced to disk before the command runs.
+type journal struct {
+	path     string
+	file     *os.File
+	sequence int // Of the last record
+}
+
+// Open the journal at path, creating it if needed, and return its records. A
+// torn record at the end of the journal, left by a crash while it was being
+// written, is dropped. A bad record followed by good ones is an error.
+func openJournal(path string) (*journal, []journalRecord, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, size, err := readJournal(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%v: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.Size() > size {
+		log.Printf("Dropping a torn record at the end of journal %v", path)
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+
+	j := &journal{path: path, file: f}
+	if len(records) > 0 {
+		j.sequence = records[len(records)-1].Sequence
+	}
+	return j, records, nil
+}
+
+// Read the good records of a journal, and the size of the journal up to the
+// end of the last one
+func readJournal(r io.Reader) ([]journalRecord, int64, error) {
+	var records []journalRecord
+	var size int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return records, size, nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		record, ok := decodeJournalRecord(line)
+		if ok && len(records) > 0 && record.Sequence != records[len(records)-1].Sequence+1 {
+			ok = false
+		}
+		if !ok {
+			// Only a torn tail is dropped; a good record after a bad one
+			// means the journal was damaged in the middle
+			if hasGoodJournalRecord(reader) {
+				return nil, 0, fmt.Errorf("%w at record %v", errJournalCorrupt, len(records)+1)
+			}
+			return records, size, nil
+		}
+		records = append(records, record)
+		size += int64(len(line))
+	}
+}
+
+// Report whether any of the remaining lines holds a good record
+func hasGoodJournalRecord(reader *bufio.Reader) bool {
+	for {
+		line, err := reader.ReadBytes('\n')
+		if _, ok := decodeJournalRecord(line); ok {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// Decode a journal line, checking that it is complete and its checksum matches
+func decodeJournalRecord(line []byte) (journalRecord, bool) {
+	var record journalRecord
+	line, ok := bytes.CutSuffix(line, []byte("\n"))
+	if !ok {
+		return record, false
+	}
+	checksum, payload, ok := bytes.Cut(line, []byte(" "))
+	if !ok || string(checksum) != fmt.Sprintf("%08x", crc32.ChecksumIEEE(payload)) {
+		return record, false
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, false
+	}
+	if (record.Command == "") == (len(record.Snapshot) == 0) {
+		return record, false
+	}
+	return record, true
+}
+
+// Encode a journal record as a line
+func encodeJournalRecord(record journalRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload)), nil
+}
+
+// Append a command to the journal and sync it to disk
+func (j *journal) append(now time.Time, command string) error {
+	line, err := encodeJournalRecord(journalRecord{Sequence: j.sequence + 1, Time: now, Command: command})
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(line); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.sequence++
+	return nil
+}
+
+// Replace the records of the journal with a snapshot of the parking lot. The
+// compacted journal is written to a temporary file first and renamed over the
+// journal, so a crash leaves either the old journal or the new one.
+func (j *journal) compact(pl *parkinglot.ParkingLot, now time.Time) error {
+	var line []byte
+	if pl.Capacity() > 0 {
+		var saved, snapshot bytes.Buffer
+		if err := pl.SaveSnapshot(&saved); err != nil {
+			return err
+		}
+		if err := json.Compact(&snapshot, saved.Bytes()); err != nil {
+			return err
+		}
+		var err error
+		line, err = encodeJournalRecord(journalRecord{Sequence: j.sequence + 1, Time: now, Snapshot: snapshot.Bytes(), Operator: pl.Operator()})
+		if err != nil {
+			return err
+		}
+	}
+
+	dir := filepath.Dir(j.path)
+	f, err := os.CreateTemp(dir, filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), j.path); err != nil {
+		return err
+	}
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	j.file.Close()
+	j.file = file
+	if line != nil {
+		j.sequence++
+	}
+	return nil
+}
+
+func (j *journal) Close() error {
+	return j.file.Close()
+}
+
+// Sync a directory, so that a file renamed into it stays there after a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// A clock showing the time of the journal record being replayed
+type replayClock struct {
+	now time.Time
+}
+
+func (c *replayClock) Now() time.Time {
+	return c.now
+}
+
+// Rebuild the parking lot from the records of a journal. The records are
+// replayed on a parking lot of their own, whose clock shows the time each
+// record was written, and the result and the operator on duty are copied into
+// the parking lot. A clock that can be moved is moved on to the time of the
+// last record, so that it does not go back in time.
+func replayJournal(pl *parkinglot.ParkingLot, options parkinglot.Options, defaultNewAllocator parkinglot.NewAllocator, records []journalRecord) error {
+	clock := &replayClock{}
+	options.Clock = clock
+	s := &session{lot: parkinglot.New(&options), out: io.Discard, defaultNewAllocator: defaultNewAllocator}
+	for _, record := range records {
+		clock.now = record.Time
+		if record.Snapshot != nil {
+			if err := s.lot.LoadSnapshot(bytes.NewReader(record.Snapshot)); err != nil {
+				return err
+			}
+			if record.Operator != "" {
+				s.lot.SetOperator(record.Operator)
+			}
+			continue
+		}
+		s.execute(record.Command)
+	}
+
+	pl.SetOperator(s.lot.Operator())
+	if advancer, ok := pl.Clock().(parkinglot.Advancer); ok && clock.now.After(pl.Clock().Now()) {
+		advancer.Advance(clock.now.Sub(pl.Clock().Now()))
+	}
+	if s.lot.Capacity() == 0 {
+		return nil
+	}
+	var snapshot bytes.Buffer
+	if err := s.lot.SaveSnapshot(&snapshot); err != nil {
+		return err
+	}
+	return pl.LoadSnapshot(&snapshot)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadJournal(t *testing.T) {
+	var lines []string
+	for i, command := range []string{"create_parking_lot 2", "park KA-01-HH-1234 White", "leave 1"} {
+		line, err := encodeJournalRecord(journalRecord{Sequence: i + 1, Time: testTime, Command: command})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line))
+	}
+	// Flip a character of the command, keeping the checksum
+	damaged := strings.Replace(lines[1], "1234", "1235", 1)
+	gap, err := encodeJournalRecord(journalRecord{Sequence: 5, Time: testTime, Command: "leave 2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		journal  string
+		want     int
+		wantSize int
+		wantErr  error
+	}{
+		{
+			name:    "Empty journal",
+			journal: "",
+		},
+		{
+			name:     "Good records",
+			journal:  lines[0] + lines[1] + lines[2],
+			want:     3,
+			wantSize: len(lines[0] + lines[1] + lines[2]),
+		},
+		{
+			name:     "Record cut short at the end",
+			journal:  lines[0] + lines[1] + lines[2][:20],
+			want:     2,
+			wantSize: len(lines[0] + lines[1]),
+		},
+		{
+			name:     "Record without a newline at the end",
+			journal:  lines[0] + strings.TrimSuffix(lines[1], "\n"),
+			want:     1,
+			wantSize: len(lines[0]),
+		},
+		{
+			name:     "Damaged record at the end",
+			journal:  lines[0] + damaged,
+			want:     1,
+			wantSize: len(lines[0]),
+		},
+		{
+			name:     "Record out of sequence at the end",
+			journal:  lines[0] + lines[1] + string(gap),
+			want:     2,
+			wantSize: len(lines[0] + lines[1]),
+		},
+		{
+			name:    "Damaged record in the middle",
+			journal: lines[0] + damaged + lines[2],
+			wantErr: errJournalCorrupt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, size, err := readJournal(strings.NewReader(tt.journal))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want = %v", err, tt.wantErr)
+			}
+			if len(records) != tt.want || size != int64(tt.wantSize) {
+				t.Errorf("got %v records of size %v, want %v records of size %v", len(records), size, tt.want, tt.wantSize)
+			}
+			for i, record := range records {
+				if record.Sequence != i+1 || !record.Time.Equal(testTime) {
+					t.Errorf("record %v = %+v", i, record)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
+)
+
+// Save a snapshot of the parking lot to a file. The snapshot is written to a
+// temporary file first and renamed over the file once it is on disk, so the
+// file holds either the old snapshot or the new one.
+func saveSnapshotFile(pl *parkinglot.ParkingLot, path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := pl.SaveSnapshot(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Restore the parking lot from a snapshot file
+func loadSnapshotFile(pl *parkinglot.ParkingLot, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return pl.LoadSnapshot(f)
+}
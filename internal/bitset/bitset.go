@@ -0,0 +1,147 @@
+// This package implements a set of small non-negative integers stored one bit
+// each, with a hierarchical summary to find the lowest member quickly.
+package bitset
+
+import "math/bits"
+
+const wordSize = 64
+
+// A Set holds integers from 0 to a fixed size. Above the bits of the members,
+// each summary level holds a bit for every non-zero word of the level below,
+// so that the lowest member is found in O(log n) by descending from the single
+// word at the top. The summary adds less than 1/63 to the memory of the bits.
+type Set struct {
+	levels [][]uint64 // levels[0] holds a bit per integer, the last level a single word
+	size   int
+	count  int
+}
+
+// New returns an empty set that can hold the integers from 0 to size-1.
+func New(size int) *Set {
+	s := &Set{size: size}
+	n := size
+	for {
+		words := (n + wordSize - 1) / wordSize
+		if words == 0 {
+			words = 1
+		}
+		s.levels = append(s.levels, make([]uint64, words))
+		if words == 1 {
+			return s
+		}
+		n = words
+	}
+}
+
+// NewFull returns a set holding every integer from 0 to size-1.
+func NewFull(size int) *Set {
+	s := New(size)
+	n := size
+	for _, level := range s.levels {
+		for w := range level {
+			bitsInWord := n - w*wordSize
+			if bitsInWord >= wordSize {
+				level[w] = ^uint64(0)
+			} else {
+				level[w] = 1<<uint(bitsInWord) - 1
+			}
+		}
+		n = len(level)
+	}
+	s.count = size
+	return s
+}
+
+// Size returns the number of integers the set can hold.
+func (s *Set) Size() int { return s.size }
+
+// Len returns the number of integers in the set.
+func (s *Set) Len() int { return s.count }
+
+// Contains reports whether i is in the set.
+func (s *Set) Contains(i int) bool {
+	if i < 0 || i >= s.size {
+		return false
+	}
+	return s.levels[0][i/wordSize]&(1<<uint(i%wordSize)) != 0
+}
+
+// Add adds i to the set. It panics if i is out of range.
+func (s *Set) Add(i int) {
+	if i < 0 || i >= s.size {
+		panic("bitset: index out of range")
+	}
+	if s.Contains(i) {
+		return
+	}
+	s.count++
+	for _, level := range s.levels {
+		w := i / wordSize
+		wasEmpty := level[w] == 0
+		level[w] |= 1 << uint(i%wordSize)
+		if !wasEmpty {
+			return
+		}
+		i = w
+	}
+}
+
+// Remove removes i from the set, if it is in the set.
+func (s *Set) Remove(i int) {
+	if !s.Contains(i) {
+		return
+	}
+	s.count--
+	for _, level := range s.levels {
+		w := i / wordSize
+		level[w] &^= 1 << uint(i%wordSize)
+		if level[w] != 0 {
+			return
+		}
+		i = w
+	}
+}
+
+// Next returns the lowest integer in the set that is at least i. It reports
+// false if there is none. Like Min, it climbs the summary levels only as far as
+// needed, so it takes O(log n).
+func (s *Set) Next(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+	if i >= s.size {
+		return 0, false
+	}
+	l := 0
+	for {
+		w := i / wordSize
+		if rest := s.levels[l][w] >> uint(i%wordSize); rest != 0 {
+			i += bits.TrailingZeros64(rest)
+			break
+		}
+		// Look for the next non-zero word of this level one level up
+		l++
+		i = w + 1
+		if l == len(s.levels) || i >= len(s.levels[l-1]) {
+			return 0, false
+		}
+	}
+	for ; l > 0; l-- {
+		i = i*wordSize + bits.TrailingZeros64(s.levels[l-1][i])
+	}
+	return i, true
+}
+
+// Min returns the lowest integer in the set. It reports false if the set is
+// empty.
+func (s *Set) Min() (int, bool) {
+	top := len(s.levels) - 1
+	if s.levels[top][0] == 0 {
+		return 0, false
+	}
+	i := 0
+	for l := top; l >= 0; l-- {
+		i = i*wordSize + bits.TrailingZeros64(s.levels[l][i])
+	}
+	return i, true
+}
@@ -0,0 +1,164 @@
+package bitset
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		add     []int
+		remove  []int
+		wantMin int
+		wantOk  bool
+		wantLen int
+	}{
+		{name: "Empty set", size: 10, wantOk: false},
+		{name: "Set with no room", size: 0, wantOk: false},
+		{name: "Single member", size: 10, add: []int{7}, wantMin: 7, wantOk: true, wantLen: 1},
+		{name: "Lowest of several members", size: 100, add: []int{99, 64, 65}, wantMin: 64, wantOk: true, wantLen: 3},
+		{name: "Adding a member twice", size: 10, add: []int{3, 3}, wantMin: 3, wantOk: true, wantLen: 1},
+		{name: "Removing the lowest member", size: 5000, add: []int{1, 4095, 4096}, remove: []int{1}, wantMin: 4095, wantOk: true, wantLen: 2},
+		{name: "Removing every member", size: 5000, add: []int{0, 4999}, remove: []int{0, 4999}, wantOk: false},
+		{name: "Removing a missing member", size: 10, add: []int{2}, remove: []int{5, -1, 10}, wantMin: 2, wantOk: true, wantLen: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.size)
+			for _, i := range tt.add {
+				s.Add(i)
+			}
+			for _, i := range tt.remove {
+				s.Remove(i)
+			}
+			got, ok := s.Min()
+			if ok != tt.wantOk || (ok && got != tt.wantMin) {
+				t.Errorf("Min() got = %v, %v, want = %v, %v", got, ok, tt.wantMin, tt.wantOk)
+			}
+			if s.Len() != tt.wantLen {
+				t.Errorf("Len() got = %v, want = %v", s.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestNewFull(t *testing.T) {
+	for _, size := range []int{1, 63, 64, 65, 4096, 4097, 300000} {
+		s := NewFull(size)
+		if s.Len() != size || s.Size() != size {
+			t.Errorf("NewFull(%v) Len() = %v, Size() = %v", size, s.Len(), s.Size())
+		}
+		for i := 0; i < size; i++ {
+			if got, ok := s.Min(); !ok || got != i {
+				t.Fatalf("NewFull(%v) Min() got = %v, want = %v", size, got, i)
+			}
+			s.Remove(i)
+		}
+		if _, ok := s.Min(); ok {
+			t.Errorf("NewFull(%v) Min() after removing every member ok = %v, want = %v", size, ok, false)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		add    []int
+		from   int
+		want   int
+		wantOk bool
+	}{
+		{name: "Empty set", size: 10, from: 0, wantOk: false},
+		{name: "Member at the start", size: 10, add: []int{3}, from: 3, want: 3, wantOk: true},
+		{name: "Member later in the word", size: 10, add: []int{1, 8}, from: 2, want: 8, wantOk: true},
+		{name: "Member in a later word", size: 5000, add: []int{1, 4500}, from: 2, want: 4500, wantOk: true},
+		{name: "Member in the last word", size: 300000, add: []int{299999}, from: 64, want: 299999, wantOk: true},
+		{name: "No member after", size: 5000, add: []int{1, 4500}, from: 4501, wantOk: false},
+		{name: "Negative start", size: 10, add: []int{0}, from: -5, want: 0, wantOk: true},
+		{name: "Start past the end", size: 10, add: []int{9}, from: 10, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.size)
+			for _, i := range tt.add {
+				s.Add(i)
+			}
+			got, ok := s.Next(tt.from)
+			if ok != tt.wantOk || (ok && got != tt.want) {
+				t.Errorf("Next(%v) got = %v, %v, want = %v, %v", tt.from, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestAddOutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Add() out of range did not panic")
+		}
+	}()
+	New(10).Add(10)
+}
+
+// Check random additions and removals against a map
+func TestSetRandom(t *testing.T) {
+	const size = 100000
+	r := rand.New(rand.NewSource(1))
+	s := New(size)
+	want := make(map[int]bool)
+
+	for i := 0; i < 200000; i++ {
+		n := r.Intn(size)
+		if r.Intn(2) == 0 {
+			s.Add(n)
+			want[n] = true
+		} else {
+			s.Remove(n)
+			delete(want, n)
+		}
+		if s.Contains(n) != want[n] || s.Len() != len(want) {
+			t.Fatalf("Contains(%v) got = %v, Len() got = %v, want = %v, %v", n, s.Contains(n), s.Len(), want[n], len(want))
+		}
+	}
+
+	var sorted []int
+	for n := range want {
+		sorted = append(sorted, n)
+	}
+	sort.Ints(sorted)
+	var walked []int
+	for n, ok := s.Next(0); ok; n, ok = s.Next(n + 1) {
+		walked = append(walked, n)
+	}
+	if !reflect.DeepEqual(walked, sorted) {
+		t.Fatalf("Next() walked %v members, want = %v", len(walked), len(sorted))
+	}
+	for _, n := range sorted {
+		if got, ok := s.Min(); !ok || got != n {
+			t.Fatalf("Min() got = %v, want = %v", got, n)
+		}
+		s.Remove(n)
+	}
+	if _, ok := s.Min(); ok {
+		t.Errorf("Min() after removing every member ok = %v, want = %v", ok, false)
+	}
+}
+
+const benchmarkSize = 1000000
+
+func BenchmarkSetMin(b *testing.B) {
+	s := NewFull(benchmarkSize)
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n, _ := s.Min()
+		s.Remove(n)
+		s.Add(r.Intn(benchmarkSize))
+	}
+}
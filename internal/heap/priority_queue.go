@@ -1,39 +1,136 @@
-// This package implements a priority queue built using the heap interface.
+// This package implements an indexed priority queue of comparable values
+// ordered by a less function.
 package heap
 
-// An Item is something we manage in a priority queue.
-type Item struct {
-	Value int // The priority of the item in the queue.
+// A PriorityQueue holds distinct values, the least first according to its less
+// function. It keeps track of the position of each value in the heap, so that
+// any value can be looked up, removed or moved after its ordering changed in
+// O(log n) without rebuilding the heap.
+type PriorityQueue[T comparable] struct {
+	items     []T
+	positions map[T]int // Position in items of each value
+	less      func(a, b T) bool
 }
 
-// A PriorityQueue implements heap.Interface and holds Items.
-type PriorityQueue []*Item
+// New returns an empty queue ordered by less, which must be a strict weak
+// ordering and may not change for a value while it is in the queue, other than
+// through Fix.
+func New[T comparable](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{items: []T{}, positions: make(map[T]int), less: less}
+}
+
+// Len returns the number of values in the queue.
+func (pq *PriorityQueue[T]) Len() int { return len(pq.items) }
+
+// Contains reports whether the value is in the queue.
+func (pq *PriorityQueue[T]) Contains(value T) bool {
+	_, ok := pq.positions[value]
+	return ok
+}
 
-// Len returns the number of elements in the heap.
-func (pq PriorityQueue) Len() int { return len(pq) }
+// Peek returns the least value without removing it.
+func (pq *PriorityQueue[T]) Peek() (T, bool) {
+	if len(pq.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return pq.items[0], true
+}
+
+// Push adds a value to the queue. A value already in the queue is moved to
+// restore the ordering instead, as by Fix.
+func (pq *PriorityQueue[T]) Push(value T) {
+	if pq.Fix(value) {
+		return
+	}
+	pq.items = append(pq.items, value)
+	pq.positions[value] = len(pq.items) - 1
+	pq.up(len(pq.items) - 1)
+}
+
+// Pop removes and returns the least value.
+func (pq *PriorityQueue[T]) Pop() (T, bool) {
+	value, ok := pq.Peek()
+	if ok {
+		pq.removeAt(0)
+	}
+	return value, ok
+}
+
+// Remove removes the value. It reports whether the value was in the queue.
+func (pq *PriorityQueue[T]) Remove(value T) bool {
+	i, ok := pq.positions[value]
+	if !ok {
+		return false
+	}
+	pq.removeAt(i)
+	return true
+}
+
+// Fix restores the ordering after the value changed how it compares to the
+// others. It reports whether the value was in the queue.
+func (pq *PriorityQueue[T]) Fix(value T) bool {
+	i, ok := pq.positions[value]
+	if !ok {
+		return false
+	}
+	if !pq.down(i) {
+		pq.up(i)
+	}
+	return true
+}
 
-func (pq PriorityQueue) Less(i, j int) bool {
-	// We want Pop to give us the lowest, not highest, priority so we use lesser than here.
-	return pq[i].Value < pq[j].Value
+// Remove the value at position i by moving the last value into its place.
+func (pq *PriorityQueue[T]) removeAt(i int) {
+	n := len(pq.items) - 1
+	if i != n {
+		pq.swap(i, n)
+	}
+	delete(pq.positions, pq.items[n])
+	var zero T
+	pq.items[n] = zero // avoid memory leak
+	pq.items = pq.items[:n]
+	if i != n && !pq.down(i) {
+		pq.up(i)
+	}
 }
 
-// Swap swaps two elements in the heap.
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
+func (pq *PriorityQueue[T]) swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.positions[pq.items[i]] = i
+	pq.positions[pq.items[j]] = j
 }
 
-// Push pushes the element x onto the heap.
-func (pq *PriorityQueue) Push(x interface{}) {
-	item := x.(*Item)
-	*pq = append(*pq, item)
+// Move the value at position j up until its parent is less.
+func (pq *PriorityQueue[T]) up(j int) {
+	for j > 0 {
+		i := (j - 1) / 2 // parent
+		if !pq.less(pq.items[j], pq.items[i]) {
+			break
+		}
+		pq.swap(i, j)
+		j = i
+	}
 }
 
-// Pop removes and returns the minimum element (according to Less) from the heap.
-func (pq *PriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil // avoid memory leak
-	*pq = old[0 : n-1]
-	return item
+// Move the value at position i0 down until its children are not less. It
+// reports whether the value moved.
+func (pq *PriorityQueue[T]) down(i0 int) bool {
+	i := i0
+	n := len(pq.items)
+	for {
+		j := 2*i + 1 // left child
+		if j >= n {
+			break
+		}
+		if j2 := j + 1; j2 < n && pq.less(pq.items[j2], pq.items[j]) {
+			j = j2 // right child
+		}
+		if !pq.less(pq.items[j], pq.items[i]) {
+			break
+		}
+		pq.swap(i, j)
+		i = j
+	}
+	return i > i0
 }
@@ -0,0 +1,244 @@
+package heap
+
+import (
+	"container/heap"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// An item with a priority, ordered by priority and then value
+type item struct {
+	value    int
+	priority int
+}
+
+func lessItem(a, b item) bool {
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.value < b.value
+}
+
+func lessInt(a, b int) bool { return a < b }
+
+// Pop every value from the queue, least first
+func drain[T comparable](pq *PriorityQueue[T]) []T {
+	var values []T
+	for {
+		value, ok := pq.Pop()
+		if !ok {
+			return values
+		}
+		values = append(values, value)
+	}
+}
+
+func TestPriorityQueue(t *testing.T) {
+	values := []int{5, 3, 8, 1, 9, 2}
+
+	tests := []struct {
+		name  string
+		apply func(pq *PriorityQueue[int]) bool
+		ok    bool
+		want  []int
+	}{
+		{
+			name:  "Pop least first",
+			apply: func(pq *PriorityQueue[int]) bool { return true },
+			ok:    true,
+			want:  []int{1, 2, 3, 5, 8, 9},
+		},
+		{
+			name:  "Remove the least value",
+			apply: func(pq *PriorityQueue[int]) bool { return pq.Remove(1) },
+			ok:    true,
+			want:  []int{2, 3, 5, 8, 9},
+		},
+		{
+			name:  "Remove a value in the middle",
+			apply: func(pq *PriorityQueue[int]) bool { return pq.Remove(5) },
+			ok:    true,
+			want:  []int{1, 2, 3, 8, 9},
+		},
+		{
+			name:  "Remove the last value in the heap",
+			apply: func(pq *PriorityQueue[int]) bool { return pq.Remove(2) },
+			ok:    true,
+			want:  []int{1, 3, 5, 8, 9},
+		},
+		{
+			name:  "Remove a missing value",
+			apply: func(pq *PriorityQueue[int]) bool { return pq.Remove(4) },
+			ok:    false,
+			want:  []int{1, 2, 3, 5, 8, 9},
+		},
+		{
+			name:  "Push an existing value",
+			apply: func(pq *PriorityQueue[int]) bool { pq.Push(8); return pq.Len() == 6 },
+			ok:    true,
+			want:  []int{1, 2, 3, 5, 8, 9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq := New(lessInt)
+			for _, value := range values {
+				pq.Push(value)
+			}
+			if ok := tt.apply(pq); ok != tt.ok {
+				t.Errorf("ok = %v, want = %v", ok, tt.ok)
+			}
+			if got := drain(pq); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueFix(t *testing.T) {
+	// Values are slot numbers ordered by a distance the caller keeps
+	distances := map[int]int{1: 4, 2: 1, 3: 3, 4: 2}
+	pq := New(func(a, b int) bool { return distances[a] < distances[b] })
+	for value := range distances {
+		pq.Push(value)
+	}
+
+	distances[1] = 0
+	distances[2] = 5
+	if !pq.Fix(1) || !pq.Fix(2) {
+		t.Fatalf("Fix() got = %v, want = %v", false, true)
+	}
+	if pq.Fix(5) {
+		t.Errorf("Fix() of a missing value got = %v, want = %v", true, false)
+	}
+	if got, want := drain(pq), []int{1, 4, 3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestPriorityQueuePeekAndContains(t *testing.T) {
+	pq := New(lessItem)
+	if _, ok := pq.Peek(); ok {
+		t.Errorf("Peek() on an empty queue ok = %v, want = %v", ok, false)
+	}
+	if _, ok := pq.Pop(); ok {
+		t.Errorf("Pop() on an empty queue ok = %v, want = %v", ok, false)
+	}
+
+	pq.Push(item{value: 7, priority: 2})
+	pq.Push(item{value: 3, priority: 4})
+	if got, ok := pq.Peek(); !ok || got != (item{value: 7, priority: 2}) || pq.Len() != 2 {
+		t.Errorf("Peek() got = %v, want = %v without removing it", got, item{value: 7, priority: 2})
+	}
+	if !pq.Contains(item{value: 3, priority: 4}) || pq.Contains(item{value: 3, priority: 2}) {
+		t.Errorf("Contains() got the values in the queue wrong")
+	}
+	pq.Remove(item{value: 3, priority: 4})
+	if pq.Contains(item{value: 3, priority: 4}) {
+		t.Errorf("Contains() got = %v after Remove(), want = %v", true, false)
+	}
+}
+
+// Check that random pushes and removals pop in the same order as sorting
+func TestPriorityQueueRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pq := New(lessItem)
+	want := make(map[item]bool)
+
+	for i := 0; i < 10000; i++ {
+		value := item{value: r.Intn(500), priority: r.Intn(100)}
+		if r.Intn(2) == 0 {
+			pq.Push(value)
+			want[value] = true
+			continue
+		}
+		if got := pq.Remove(value); got != want[value] {
+			t.Fatalf("Remove(%v) got = %v, want = %v", value, got, want[value])
+		}
+		delete(want, value)
+	}
+
+	var sorted []item
+	for value := range want {
+		sorted = append(sorted, value)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return lessItem(sorted[i], sorted[j]) })
+	if got := drain(pq); !reflect.DeepEqual(got, sorted) {
+		t.Errorf("got = %v, want = %v", got, sorted)
+	}
+}
+
+// A heap of items through container/heap, to benchmark against
+type itemHeap []*item
+
+func (h itemHeap) Len() int            { return len(h) }
+func (h itemHeap) Less(i, j int) bool  { return lessItem(*h[i], *h[j]) }
+func (h itemHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *itemHeap) Push(x interface{}) { *h = append(*h, x.(*item)) }
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+const benchmarkSize = 10000
+
+func BenchmarkContainerHeapPushPop(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		h := itemHeap{}
+		for v := 0; v < benchmarkSize; v++ {
+			heap.Push(&h, &item{value: v, priority: (v * 7919) % benchmarkSize})
+		}
+		for h.Len() > 0 {
+			heap.Pop(&h)
+		}
+	}
+}
+
+func BenchmarkPriorityQueuePushPop(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		pq := New(lessItem)
+		for v := 0; v < benchmarkSize; v++ {
+			pq.Push(item{value: v, priority: (v * 7919) % benchmarkSize})
+		}
+		for pq.Len() > 0 {
+			pq.Pop()
+		}
+	}
+}
+
+// Removing a value through container/heap needs a linear search for its position
+func BenchmarkContainerHeapRemove(b *testing.B) {
+	h := itemHeap{}
+	for v := 0; v < benchmarkSize; v++ {
+		heap.Push(&h, &item{value: v, priority: v})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		value := (i * 7919) % benchmarkSize
+		for j, x := range h {
+			if x.value == value {
+				heap.Remove(&h, j)
+				break
+			}
+		}
+		heap.Push(&h, &item{value: value, priority: value})
+	}
+}
+
+func BenchmarkPriorityQueueRemove(b *testing.B) {
+	pq := New(lessItem)
+	for v := 0; v < benchmarkSize; v++ {
+		pq.Push(item{value: v, priority: v})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		value := (i * 7919) % benchmarkSize
+		pq.Remove(item{value: value, priority: value})
+		pq.Push(item{value: value, priority: value})
+	}
+}
@@ -0,0 +1,106 @@
+// This package implements a set of integers stored as sorted, disjoint intervals.
+package intervalset
+
+import "sort"
+
+// An Interval holds every integer from Start to End, both inclusive.
+type Interval struct {
+	Start int
+	End   int
+}
+
+// Len returns the number of integers in the interval.
+func (iv Interval) Len() int { return iv.End - iv.Start + 1 }
+
+// A Set holds integers as sorted, disjoint and non-adjacent intervals.
+type Set struct {
+	intervals []Interval
+}
+
+// New returns a set holding every integer from start to end, both inclusive.
+func New(start, end int) *Set {
+	s := &Set{}
+	if start <= end {
+		s.intervals = []Interval{{start, end}}
+	}
+	return s
+}
+
+// Intervals returns the intervals of the set in ascending order.
+func (s *Set) Intervals() []Interval {
+	return s.intervals
+}
+
+// Len returns the number of integers in the set.
+func (s *Set) Len() int {
+	n := 0
+	for _, iv := range s.intervals {
+		n += iv.Len()
+	}
+	return n
+}
+
+// search returns the index of the first interval that ends at or after n.
+func (s *Set) search(n int) int {
+	return sort.Search(len(s.intervals), func(i int) bool {
+		return s.intervals[i].End >= n
+	})
+}
+
+// Contains reports whether n is in the set.
+func (s *Set) Contains(n int) bool {
+	i := s.search(n)
+	return i < len(s.intervals) && s.intervals[i].Start <= n
+}
+
+// Add adds n to the set, merging it with adjacent intervals.
+func (s *Set) Add(n int) {
+	i := s.search(n - 1)
+	if i < len(s.intervals) && s.intervals[i].Start <= n && n <= s.intervals[i].End {
+		return // Already in the set
+	}
+
+	joinsPrev := i < len(s.intervals) && s.intervals[i].End == n-1
+	nextIdx := i
+	if joinsPrev {
+		nextIdx = i + 1
+	}
+	joinsNext := nextIdx < len(s.intervals) && s.intervals[nextIdx].Start == n+1
+
+	switch {
+	case joinsPrev && joinsNext:
+		s.intervals[i].End = s.intervals[nextIdx].End
+		s.intervals = append(s.intervals[:nextIdx], s.intervals[nextIdx+1:]...)
+	case joinsPrev:
+		s.intervals[i].End = n
+	case joinsNext:
+		s.intervals[nextIdx].Start = n
+	default:
+		s.intervals = append(s.intervals, Interval{})
+		copy(s.intervals[i+1:], s.intervals[i:])
+		s.intervals[i] = Interval{n, n}
+	}
+}
+
+// Remove removes n from the set, splitting the interval that holds it.
+func (s *Set) Remove(n int) {
+	i := s.search(n)
+	if i == len(s.intervals) || s.intervals[i].Start > n {
+		return // Not in the set
+	}
+
+	iv := s.intervals[i]
+	switch {
+	case iv.Start == n && iv.End == n:
+		s.intervals = append(s.intervals[:i], s.intervals[i+1:]...)
+	case iv.Start == n:
+		s.intervals[i].Start = n + 1
+	case iv.End == n:
+		s.intervals[i].End = n - 1
+	default:
+		s.intervals = append(s.intervals, Interval{})
+		copy(s.intervals[i+1:], s.intervals[i:])
+		s.intervals[i] = Interval{iv.Start, n - 1}
+		s.intervals[i+1] = Interval{n + 1, iv.End}
+	}
+}
@@ -0,0 +1,127 @@
+package intervalset
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   int
+		end     int
+		add     []int
+		remove  []int
+		want    []Interval
+		wantLen int
+	}{
+		{name: "Empty set", start: 1, end: 0},
+		{name: "Full range", start: 1, end: 5, want: []Interval{{1, 5}}, wantLen: 5},
+		{name: "Single member", start: 1, end: 0, add: []int{7}, want: []Interval{{7, 7}}, wantLen: 1},
+		{name: "Adding a member twice", start: 1, end: 0, add: []int{3, 3}, want: []Interval{{3, 3}}, wantLen: 1},
+		{name: "Adding a member inside an interval", start: 1, end: 5, add: []int{1, 3, 5}, want: []Interval{{1, 5}}, wantLen: 5},
+		{name: "Disjoint members", start: 1, end: 0, add: []int{9, 1, 5}, want: []Interval{{1, 1}, {5, 5}, {9, 9}}, wantLen: 3},
+		{name: "Merging with the interval before", start: 1, end: 3, add: []int{4}, want: []Interval{{1, 4}}, wantLen: 4},
+		{name: "Merging with the interval after", start: 5, end: 8, add: []int{4}, want: []Interval{{4, 8}}, wantLen: 5},
+		{name: "Merging two intervals", start: 1, end: 0, add: []int{1, 2, 4, 5, 3}, want: []Interval{{1, 5}}, wantLen: 5},
+		{name: "Removing the only member", start: 1, end: 1, remove: []int{1}},
+		{name: "Removing the start of an interval", start: 1, end: 5, remove: []int{1}, want: []Interval{{2, 5}}, wantLen: 4},
+		{name: "Removing the end of an interval", start: 1, end: 5, remove: []int{5}, want: []Interval{{1, 4}}, wantLen: 4},
+		{name: "Splitting an interval", start: 1, end: 5, remove: []int{3}, want: []Interval{{1, 2}, {4, 5}}, wantLen: 4},
+		{name: "Splitting an interval twice", start: 1, end: 9, remove: []int{3, 7}, want: []Interval{{1, 2}, {4, 6}, {8, 9}}, wantLen: 7},
+		{name: "Removing a missing member", start: 1, end: 5, remove: []int{0, 6, 3, 3}, want: []Interval{{1, 2}, {4, 5}}, wantLen: 4},
+		{name: "Splitting and merging again", start: 1, end: 5, remove: []int{2, 4}, add: []int{2, 4}, want: []Interval{{1, 5}}, wantLen: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.start, tt.end)
+			for _, n := range tt.remove {
+				s.Remove(n)
+			}
+			for _, n := range tt.add {
+				s.Add(n)
+			}
+			if got := s.Intervals(); !reflect.DeepEqual(got, tt.want) && (len(got) > 0 || len(tt.want) > 0) {
+				t.Errorf("Intervals() got = %v, want = %v", got, tt.want)
+			}
+			if got := s.Len(); got != tt.wantLen {
+				t.Errorf("Len() got = %v, want = %v", got, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	s := New(1, 10)
+	s.Remove(5)
+	s.Remove(6)
+	s.Add(20)
+	// The set is {1-4, 7-10, 20}
+	tests := []struct {
+		n    int
+		want bool
+	}{
+		{n: 0, want: false},
+		{n: 1, want: true},
+		{n: 4, want: true},
+		{n: 5, want: false},
+		{n: 6, want: false},
+		{n: 7, want: true},
+		{n: 10, want: true},
+		{n: 11, want: false},
+		{n: 19, want: false},
+		{n: 20, want: true},
+		{n: 21, want: false},
+	}
+	for _, tt := range tests {
+		if got := s.Contains(tt.n); got != tt.want {
+			t.Errorf("Contains(%v) got = %v, want = %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+// Add and remove members at random, and compare the set with a map
+func TestSetRandom(t *testing.T) {
+	const size = 200
+	r := rand.New(rand.NewSource(1))
+	s := &Set{}
+	members := make(map[int]bool)
+	for i := 0; i < 5000; i++ {
+		n := r.Intn(size)
+		if r.Intn(2) == 0 {
+			s.Add(n)
+			members[n] = true
+		} else {
+			s.Remove(n)
+			delete(members, n)
+		}
+	}
+
+	var want []int
+	for n := range members {
+		want = append(want, n)
+	}
+	sort.Ints(want)
+	var got []int
+	for i, iv := range s.Intervals() {
+		if iv.Start > iv.End || (i > 0 && iv.Start <= s.Intervals()[i-1].End+1) {
+			t.Fatalf("Intervals() got %v, not sorted, disjoint and non-adjacent", s.Intervals())
+		}
+		for n := iv.Start; n <= iv.End; n++ {
+			got = append(got, n)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Intervals() got members %v, want = %v", got, want)
+	}
+	if s.Len() != len(want) {
+		t.Errorf("Len() got = %v, want = %v", s.Len(), len(want))
+	}
+	for n := -1; n <= size; n++ {
+		if s.Contains(n) != members[n] {
+			t.Errorf("Contains(%v) got = %v, want = %v", n, s.Contains(n), members[n])
+		}
+	}
+}
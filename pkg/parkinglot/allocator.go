@@ -0,0 +1,529 @@
+package parkinglot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cedrickchee/go-parkinglot/internal/bitset"
+	qheap "github.com/cedrickchee/go-parkinglot/internal/heap"
+)
+
+// A SizePolicy decides whether a vehicle may park in a slot larger than it needs
+type SizePolicy int
+
+const (
+	ExactSize   SizePolicy = iota // Vehicles only park in slots of their own size
+	AllowLarger                   // Vehicles fall back to a larger slot when none of their size is free
+)
+
+var sizePolicyNames = []string{"exact", "larger"}
+
+// Parse a size policy name, such as "larger"
+func ParseSizePolicy(name string) (SizePolicy, error) {
+	for i, policyName := range sizePolicyNames {
+		if strings.EqualFold(name, policyName) {
+			return SizePolicy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown size policy: %v", name)
+}
+
+func (p SizePolicy) String() string {
+	return sizePolicyNames[p]
+}
+
+// A slotKey orders free slots by the rank the allocation strategy gave them,
+// or their distance from a gate. Ties go to the least worn slot, then to the
+// lowest floor and slot number.
+type slotKey struct {
+	rank        int
+	useCount    int
+	floorNumber int
+	slotNumber  int
+}
+
+func newSlotKey(slot *Slot, rank int) slotKey {
+	return slotKey{
+		rank:        rank,
+		useCount:    slot.UseCount(),
+		floorNumber: slot.FloorNumber(),
+		slotNumber:  slot.SlotNumber(),
+	}
+}
+
+// Reports whether the key orders before another
+func (k slotKey) less(other slotKey) bool {
+	if k.rank != other.rank {
+		return k.rank < other.rank
+	}
+	if k.useCount != other.useCount {
+		return k.useCount < other.useCount
+	}
+	if k.floorNumber != other.floorNumber {
+		return k.floorNumber < other.floorNumber
+	}
+	return k.slotNumber < other.slotNumber
+}
+
+// A slotPool hands out free slots of one size and set of attributes, lowest
+// key first. Slots are handed out in order until each one has been used
+// once, after that freed slots are reused from the emptySlot heap. A pool
+// whose ranks never change and are all distinct keeps a compact bitset of the
+// free positions in order instead.
+type slotPool struct {
+	size        SlotSize
+	attributes  Attributes
+	emptySlot   *qheap.PriorityQueue[slotKey]
+	keys        map[int]slotKey // Key of each slot number in the emptySlot heap
+	compact     *bitset.Set     // Free positions in order, nil if the emptySlot heap is used
+	order       []*Slot         // Slots in the pool, lowest rank first, each knowing its position
+	ranks       []int           // Rank of each slot in order when the pool was created, nil if compact
+	rank        func(*Slot) int // Ranks the slots of a compact pool, whose ranks never change
+	highestSlot int             // Slots in order before highestSlot have been handed out at least once
+	gates       []*gateQueue
+}
+
+func newSlotPool(size SlotSize, attributes Attributes) *slotPool {
+	return &slotPool{
+		size:       size,
+		attributes: attributes,
+		emptySlot:  qheap.New(slotKey.less),
+		keys:       make(map[int]slotKey),
+	}
+}
+
+// Returns the rank of the slot at a position in order
+func (p *slotPool) getRank(i int) int {
+	if p.ranks == nil {
+		return p.rank(p.order[i])
+	}
+	return p.ranks[i]
+}
+
+// Reports whether the ranks of the slots in the pool are all distinct
+func (p *slotPool) hasDistinctRanks() bool {
+	for i := 1; i < len(p.ranks); i++ {
+		if p.ranks[i] == p.ranks[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the key of the free slot with the lowest key in the pool without taking it
+func (p *slotPool) peek() (slotKey, bool) {
+	if p.compact != nil {
+		i, ok := p.compact.Min()
+		if !ok {
+			return slotKey{}, false
+		}
+		return newSlotKey(p.order[i], p.getRank(i)), true
+	}
+
+	top, fromHeap := p.emptySlot.Peek()
+	fromOrder := p.highestSlot < len(p.order)
+	if fromHeap && fromOrder {
+		// A freed slot may rank below or above the slots never handed out
+		if p.next().less(top) {
+			fromHeap = false
+		}
+	}
+	if fromHeap {
+		return top, true
+	}
+	if fromOrder {
+		return p.next(), true
+	}
+	return slotKey{}, false
+}
+
+// Returns the key of the next slot in order that has never been handed out
+func (p *slotPool) next() slotKey {
+	return newSlotKey(p.order[p.highestSlot], p.getRank(p.highestSlot))
+}
+
+// Take a given free slot out of the pool
+func (p *slotPool) take(slot *Slot) {
+	i := slot.position
+	if p.compact != nil {
+		p.compact.Remove(i)
+		if i >= p.highestSlot {
+			p.highestSlot = i + 1
+		}
+		return
+	}
+
+	if i >= p.highestSlot {
+		// The slots in order before it stay free
+		for ; p.highestSlot < i; p.highestSlot++ {
+			p.push(p.order[p.highestSlot], p.getRank(p.highestSlot))
+		}
+		p.highestSlot = i + 1
+		return
+	}
+	if key, ok := p.keys[slot.SlotNumber()]; ok {
+		p.emptySlot.Remove(key)
+		delete(p.keys, slot.SlotNumber())
+	}
+}
+
+// Return a freed slot to the pool with the given rank
+func (p *slotPool) push(slot *Slot, rank int) {
+	if p.compact != nil {
+		p.compact.Add(slot.position)
+		return
+	}
+	key := newSlotKey(slot, rank)
+	p.keys[key.slotNumber] = key
+	p.emptySlot.Push(key)
+}
+
+// Returns the number of free slots in the pool
+func (p *slotPool) getFreeCount() int {
+	if p.compact != nil {
+		return p.compact.Len()
+	}
+	return len(p.order) - p.highestSlot + p.emptySlot.Len()
+}
+
+// A slotAllocator finds the free slots that fit a vehicle, in the order its
+// Allocator strategy ranks them. Free slots are kept in a separate pool for
+// each combination of slot size and attributes. Vehicles that span several
+// slots are allocated from the set of free slots instead.
+type slotAllocator struct {
+	pools    []*slotPool // Ordered by slot size, then attributes
+	free     *bitset.Set // Free slots by slot number - 1
+	slots    []*Slot     // All slots, ordered by slot number
+	policy   SizePolicy
+	strategy Allocator
+	gates    map[string]int // Index of the queue of each gate in the pools
+}
+
+// Allocate by the allocation strategy rather than nearest to a gate
+const noGate = -1
+
+func newSlotAllocator(slots []*Slot, policy SizePolicy, strategy Allocator) *slotAllocator {
+	ranks := make([]int, len(slots))
+	for i, slot := range slots {
+		ranks[i] = strategy.Rank(slot)
+	}
+	return newRankedSlotAllocator(slots, policy, strategy, ranks)
+}
+
+// Create an allocator whose slots were given their ranks before, by slot
+// number - 1, such as when restoring a snapshot
+func newRankedSlotAllocator(slots []*Slot, policy SizePolicy, strategy Allocator, ranks []int) *slotAllocator {
+	order := make([]*Slot, len(slots))
+	copy(order, slots)
+	sort.SliceStable(order, func(i, j int) bool {
+		return ranks[order[i].SlotNumber()-1] < ranks[order[j].SlotNumber()-1]
+	})
+
+	a := &slotAllocator{
+		free:     bitset.NewFull(len(slots)),
+		slots:    slots,
+		policy:   policy,
+		strategy: strategy,
+		gates:    make(map[string]int),
+	}
+	// Count the slots of each pool first, so that very large pools are not
+	// copied over and over as they grow
+	counts := make(map[*slotPool]int)
+	for _, slot := range slots {
+		pool := a.getPool(slot)
+		if pool == nil {
+			pool = newSlotPool(slot.Size(), slot.Attributes())
+			a.pools = append(a.pools, pool)
+		}
+		counts[pool]++
+	}
+	for pool, count := range counts {
+		pool.order = make([]*Slot, 0, count)
+		pool.ranks = make([]int, 0, count)
+	}
+	for _, slot := range order {
+		pool := a.getPool(slot)
+		slot.position = len(pool.order)
+		pool.order = append(pool.order, slot)
+		pool.ranks = append(pool.ranks, ranks[slot.SlotNumber()-1])
+	}
+	sort.Slice(a.pools, func(i, j int) bool {
+		if a.pools[i].size != a.pools[j].size {
+			return a.pools[i].size < a.pools[j].size
+		}
+		return a.pools[i].attributes < a.pools[j].attributes
+	})
+	if _, ok := strategy.(staticAllocator); ok {
+		for _, pool := range a.pools {
+			if pool.hasDistinctRanks() {
+				pool.compact = bitset.NewFull(len(pool.order))
+				pool.emptySlot = nil
+				pool.keys = nil
+				pool.ranks = nil
+				pool.rank = strategy.Rank
+			}
+		}
+	}
+
+	return a
+}
+
+// Add a gate with the distance from it to each slot, by slot number
+func (a *slotAllocator) addGate(name string, distances []int) {
+	a.gates[name] = len(a.pools[0].gates)
+	for _, pool := range a.pools {
+		queue := newGateQueue(a.slots, distances)
+		for _, slot := range pool.order {
+			if a.isFree(slot) {
+				queue.push(slot.SlotNumber())
+			}
+		}
+		pool.gates = append(pool.gates, queue)
+	}
+}
+
+// Reports whether a slot is free
+func (a *slotAllocator) isFree(slot *Slot) bool {
+	return a.free.Contains(slot.SlotNumber() - 1)
+}
+
+// Get the index of a gate by name
+func (a *slotAllocator) getGate(name string) (int, bool) {
+	gate, ok := a.gates[name]
+	return gate, ok
+}
+
+// Returns the key of a free slot ranked by the allocation strategy, or by its
+// distance from the gate
+func (a *slotAllocator) getKey(slot *Slot, gate int) slotKey {
+	if gate == noGate {
+		return newSlotKey(slot, a.strategy.Rank(slot))
+	}
+	return a.pools[0].gates[gate].getKey(slot)
+}
+
+// Get the pool a slot belongs to
+func (a *slotAllocator) getPool(slot *Slot) *slotPool {
+	for _, pool := range a.pools {
+		if pool.size == slot.Size() && pool.attributes == slot.Attributes() {
+			return pool
+		}
+	}
+	return nil
+}
+
+// Returns the slot sizes a vehicle type may park in, in order of preference
+func (a *slotAllocator) getSlotSizes(vehicleType VehicleType) []SlotSize {
+	size := vehicleType.getSlotSize()
+	if a.policy == ExactSize {
+		return []SlotSize{size}
+	}
+
+	// Prefer the smallest slot that fits, to keep the larger slots free for
+	// the vehicles that need them
+	var sizes []SlotSize
+	for s := size; int(s) < len(slotSizeNames); s++ {
+		sizes = append(sizes, s)
+	}
+	return sizes
+}
+
+// Reports whether a vehicle of the given type with the given needs fits in a
+// slot on its own
+func (a *slotAllocator) fits(slot *Slot, vehicleType VehicleType, needs Attributes) bool {
+	if vehicleType.getSpan() != 1 || !slot.Attributes().has(needs) {
+		return false
+	}
+	for _, size := range a.getSlotSizes(vehicleType) {
+		if slot.Size() == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the numbers of the lowest ranked free slots that fit the vehicle type and
+// have all the attributes the vehicle needs
+func (a *slotAllocator) allocate(vehicleType VehicleType, needs Attributes) ([]int, error) {
+	return a.allocateAtGate(vehicleType, needs, noGate)
+}
+
+// Get the numbers of the free slots nearest to a gate that fit the vehicle
+// type and have all the attributes the vehicle needs
+func (a *slotAllocator) allocateAtGate(vehicleType VehicleType, needs Attributes, gate int) ([]int, error) {
+	sizes := a.getSlotSizes(vehicleType)
+	span := vehicleType.getSpan()
+
+	// Vehicles without needs keep off the special slots while general slots are free
+	passes := []func(Attributes) bool{
+		func(attributes Attributes) bool { return attributes.has(needs) },
+	}
+	if needs == 0 {
+		passes = []func(Attributes) bool{
+			func(attributes Attributes) bool { return attributes == 0 },
+			func(attributes Attributes) bool { return true },
+		}
+	}
+
+	fits := 0
+	for _, size := range sizes {
+		for _, pool := range a.pools {
+			if pool.size == size && pool.attributes.has(needs) {
+				fits += len(pool.order)
+			}
+		}
+	}
+	if fits < span {
+		return nil, &FitError{VehicleType: vehicleType, Needs: needs}
+	}
+
+	for _, matches := range passes {
+		for _, size := range sizes {
+			if span > 1 {
+				if slotNumbers := a.allocateSpan(size, span, matches, gate); slotNumbers != nil {
+					return slotNumbers, nil
+				}
+				continue
+			}
+			if slotNumber, ok := a.allocateSlot(size, matches, gate); ok {
+				return []int{slotNumber}, nil
+			}
+		}
+	}
+
+	return nil, ErrParkingLotFull
+}
+
+// Get the lowest ranked free slot of the given size with matching attributes
+func (a *slotAllocator) allocateSlot(size SlotSize, matches func(Attributes) bool, gate int) (int, bool) {
+	var best *slotPool
+	var bestKey slotKey
+
+	for _, pool := range a.pools {
+		if pool.size != size || !matches(pool.attributes) {
+			continue
+		}
+		var key slotKey
+		var ok bool
+		if gate == noGate {
+			key, ok = pool.peek()
+		} else {
+			key, ok = pool.gates[gate].peek()
+		}
+		if !ok {
+			continue
+		}
+		if best == nil || key.less(bestKey) {
+			best, bestKey = pool, key
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+
+	a.remove(a.slots[bestKey.slotNumber-1])
+	return bestKey.slotNumber, true
+}
+
+// Get the run of adjacent free slots of the given size with matching
+// attributes on a single floor whose first slot ranks lowest. Returns nil if
+// there is no such run. Every free slot is visited, as the lowest ranked run
+// may start anywhere.
+func (a *slotAllocator) allocateSpan(size SlotSize, span int, matches func(Attributes) bool, gate int) []int {
+	var best *Slot
+	var bestKey slotKey
+
+	run := 0
+	for i, ok := a.free.Min(); ok; i, ok = a.free.Next(i + 1) {
+		slot := a.slots[i]
+		switch {
+		case slot.Size() != size || !matches(slot.Attributes()):
+			run = 0
+		case run > 0 && (!a.free.Contains(i-1) || a.slots[i-1].FloorNumber() != slot.FloorNumber()):
+			run = 1
+		default:
+			run++
+		}
+		if run < span {
+			continue
+		}
+		start := a.slots[i+1-span]
+		if key := a.getKey(start, gate); best == nil || key.less(bestKey) {
+			best, bestKey = start, key
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	var slotNumbers []int
+	for n := best.SlotNumber(); n < best.SlotNumber()+span; n++ {
+		a.remove(a.slots[n-1])
+		slotNumbers = append(slotNumbers, n)
+	}
+	return slotNumbers
+}
+
+// Take a given free slot out of allocation. Reports whether it was free.
+func (a *slotAllocator) take(slot *Slot) bool {
+	if !a.isFree(slot) {
+		return false
+	}
+	a.remove(slot)
+	return true
+}
+
+// Remove a free slot from its pool, the queue of every gate and the set of
+// free slots
+func (a *slotAllocator) remove(slot *Slot) {
+	pool := a.getPool(slot)
+	pool.take(slot)
+	for _, queue := range pool.gates {
+		queue.remove(slot.SlotNumber())
+	}
+	a.free.Remove(slot.SlotNumber() - 1)
+}
+
+// Make a slot available again
+func (a *slotAllocator) release(slot *Slot) {
+	pool := a.getPool(slot)
+	pool.push(slot, a.strategy.Rank(slot))
+	for _, queue := range pool.gates {
+		queue.push(slot.SlotNumber())
+	}
+	a.free.Add(slot.SlotNumber() - 1)
+}
+
+// Returns the number of free slots with all the given attributes
+func (a *slotAllocator) getFreeCount(attributes Attributes) int {
+	count := 0
+	for _, pool := range a.pools {
+		if pool.attributes.has(attributes) {
+			count += pool.getFreeCount()
+		}
+	}
+	return count
+}
+
+// Returns the number of free slots of the sizes a vehicle type may park in
+func (a *slotAllocator) getFitCount(vehicleType VehicleType) int {
+	count := 0
+	for _, size := range a.getSlotSizes(vehicleType) {
+		for _, pool := range a.pools {
+			if pool.size == size {
+				count += pool.getFreeCount()
+			}
+		}
+	}
+	return count
+}
+
+// Get the slots that have been handed out at least once
+func (a *slotAllocator) getUsedSlots() []*Slot {
+	var slots []*Slot
+	for _, pool := range a.pools {
+		slots = append(slots, pool.order[:pool.highestSlot]...)
+	}
+	return slots
+}
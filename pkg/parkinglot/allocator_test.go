@@ -0,0 +1,535 @@
+package parkinglot
+
+import (
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+
+	qheap "github.com/cedrickchee/go-parkinglot/internal/heap"
+)
+
+// Generate slots with the given sizes, numbered in order of distance
+func generateSizedSlots(sizes ...SlotSize) []*Slot {
+	slots := generateParkingSlot(len(sizes))
+	for i, size := range sizes {
+		slots[i].size = size
+	}
+	return slots
+}
+
+func TestSlotAllocatorAllocate(t *testing.T) {
+	data := genData()
+	slots := generateParkingSlot(2)
+	state := generateAllocator(slots, data.emptySlot0, 0)
+
+	mixed := newSlotAllocator(generateSizedSlots(Large, Small, Medium, Large), ExactSize, nearestEntry{})
+	fallback := newSlotAllocator(generateSizedSlots(Large, Small, Medium, Large), AllowLarger, nearestEntry{})
+
+	tests := []struct {
+		name        string
+		allocator   *slotAllocator
+		vehicleType VehicleType
+		want        []int
+		wantErr     bool
+	}{
+		{
+			name:        "Empty parking lot with 2 available slots",
+			allocator:   state,
+			vehicleType: Car,
+			want:        []int{1},
+			wantErr:     false,
+		},
+		{
+			name:        "2 slots parking lot with 1 available slots",
+			allocator:   state,
+			vehicleType: Car,
+			want:        []int{2},
+			wantErr:     false,
+		},
+		{
+			name:        "Parking lot with unavailable slots",
+			allocator:   state,
+			vehicleType: Car,
+			want:        nil,
+			wantErr:     true,
+		},
+		{
+			name:        "Motorcycle gets the small slot",
+			allocator:   mixed,
+			vehicleType: Motorcycle,
+			want:        []int{2},
+			wantErr:     false,
+		},
+		{
+			name:        "Motorcycle does not fall back to a larger slot",
+			allocator:   mixed,
+			vehicleType: Motorcycle,
+			want:        nil,
+			wantErr:     true,
+		},
+		{
+			name:        "Van gets the nearest large slot",
+			allocator:   mixed,
+			vehicleType: Van,
+			want:        []int{1},
+			wantErr:     false,
+		},
+		{
+			name:        "Van gets the next large slot",
+			allocator:   mixed,
+			vehicleType: Van,
+			want:        []int{4},
+			wantErr:     false,
+		},
+		{
+			name:        "Motorcycle prefers a slot of its own size",
+			allocator:   fallback,
+			vehicleType: Motorcycle,
+			want:        []int{2},
+			wantErr:     false,
+		},
+		{
+			name:        "Motorcycle falls back to the smallest larger slot",
+			allocator:   fallback,
+			vehicleType: Motorcycle,
+			want:        []int{3},
+			wantErr:     false,
+		},
+		{
+			name:        "Car falls back to a large slot",
+			allocator:   fallback,
+			vehicleType: Car,
+			want:        []int{1},
+			wantErr:     false,
+		},
+		{
+			name:        "Van does not fit in a smaller slot",
+			allocator:   fallback,
+			vehicleType: Van,
+			want:        []int{4},
+			wantErr:     false,
+		},
+		{
+			name:        "Parking lot without a slot for the vehicle type",
+			allocator:   newSlotAllocator(generateSizedSlots(Small, Medium), AllowLarger, nearestEntry{}),
+			vehicleType: Bus,
+			want:        nil,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.allocator.allocate(tt.vehicleType, 0)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("allocate() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotAllocatorAllocateSpan(t *testing.T) {
+	// Large slots 1-2 and 4 on the first floor and 5-6 on the second floor,
+	// the rest medium
+	slots := generateSizedSlots(Large, Large, Medium, Large, Large, Large, Medium, Medium)
+	for _, slot := range slots[4:] {
+		slot.floorNumber = 2
+	}
+	allocator := newSlotAllocator(slots, ExactSize, nearestEntry{})
+
+	tests := []struct {
+		name        string
+		vehicleType VehicleType
+		want        []int
+		wantErr     bool
+	}{
+		{
+			name:        "Bus takes the nearest pair of large slots",
+			vehicleType: Bus,
+			want:        []int{1, 2},
+			wantErr:     false,
+		},
+		{
+			name:        "Trailer does not span floors",
+			vehicleType: Trailer,
+			want:        nil,
+			wantErr:     true,
+		},
+		{
+			name:        "Van takes the nearest large slot left",
+			vehicleType: Van,
+			want:        []int{4},
+			wantErr:     false,
+		},
+		{
+			name:        "Bus takes adjacent slots on the next floor",
+			vehicleType: Bus,
+			want:        []int{5, 6},
+			wantErr:     false,
+		},
+		{
+			name:        "No large slot left",
+			vehicleType: Van,
+			want:        nil,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allocator.allocate(tt.vehicleType, 0)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("allocate() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotAllocatorRelease(t *testing.T) {
+	allocator := newSlotAllocator(generateSizedSlots(Large, Large, Large, Large), ExactSize, nearestEntry{})
+
+	// Fill the lot with a van in slot 1, a bus in slots 2-3 and a van in slot 4
+	for _, vehicleType := range []VehicleType{Van, Bus, Van} {
+		if _, err := allocator.allocate(vehicleType, 0); err != nil {
+			t.Fatalf("allocate() error = %v", err)
+		}
+	}
+
+	// The bus leaves, followed by the van in slot 1
+	allocator.release(allocator.slots[1])
+	allocator.release(allocator.slots[2])
+	allocator.release(allocator.slots[0])
+
+	got, err := allocator.allocate(Trailer, 0)
+	if err != nil {
+		t.Fatalf("allocate() error = %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allocate() got = %v, want = %v", got, want)
+	}
+	if _, err := allocator.allocate(Van, 0); err == nil {
+		t.Errorf("allocate() error = %v, wantErr = %v", err, true)
+	}
+}
+
+// Generate slots with the given attributes, numbered in order of distance
+func generateTaggedSlots(attributes ...Attributes) []*Slot {
+	slots := generateParkingSlot(len(attributes))
+	for i, attribute := range attributes {
+		slots[i].attributes = attribute
+	}
+	return slots
+}
+
+func TestSlotAllocatorAllocateWithNeeds(t *testing.T) {
+	allocator := newSlotAllocator(generateTaggedSlots(EVCharger, Accessible|Covered, 0, EVCharger|Covered, 0), ExactSize, nearestEntry{})
+
+	tests := []struct {
+		name    string
+		needs   Attributes
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "Vehicle without needs avoids special slots",
+			needs:   0,
+			want:    []int{3},
+			wantErr: false,
+		},
+		{
+			name:    "Vehicle needing a charger gets the nearest one",
+			needs:   EVCharger,
+			want:    []int{1},
+			wantErr: false,
+		},
+		{
+			name:    "Vehicle with several needs gets a slot with all of them",
+			needs:   EVCharger | Covered,
+			want:    []int{4},
+			wantErr: false,
+		},
+		{
+			name:    "No free slot with the needed attributes",
+			needs:   EVCharger,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "No slot with the needed attributes at all",
+			needs:   Compact,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Vehicle without needs takes the last general slot",
+			needs:   0,
+			want:    []int{5},
+			wantErr: false,
+		},
+		{
+			name:    "Vehicle without needs takes a special slot when no general slot is free",
+			needs:   0,
+			want:    []int{2},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allocator.allocate(Car, tt.needs)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("allocate() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := allocator.getFreeCount(0); got != 0 {
+		t.Errorf("getFreeCount() got = %v, want = %v", got, 0)
+	}
+}
+
+// Ranks every slot the same
+type sameRank struct{}
+
+func (sameRank) Rank(slot *Slot) int {
+	return 0
+}
+
+func TestSlotKeyTies(t *testing.T) {
+	slots := generateParkingSlot(3)
+	slots[2].floorNumber = 2
+	allocator := newSlotAllocator(slots, ExactSize, sameRank{})
+
+	// Slots never handed out go by floor and slot number
+	for _, want := range []int{1, 2, 3} {
+		if got, err := allocator.allocate(Car, 0); err != nil || got[0] != want {
+			t.Fatalf("allocate() got = %v, error = %v, want = %v", got, err, want)
+		}
+	}
+
+	// Freed slots of the same rank go to the least worn first
+	for _, slot := range slots {
+		slot.useCount = []int{3, 1, 2}[slot.SlotNumber()-1]
+		allocator.release(slot)
+	}
+	for _, want := range []int{2, 3, 1} {
+		if got, err := allocator.allocate(Car, 0); err != nil || got[0] != want {
+			t.Errorf("allocate() got = %v, error = %v, want = %v", got, err, want)
+		}
+	}
+}
+
+// Create an allocator whose pools keep their free slots in the emptySlot heap,
+// even when the strategy would let them keep a compact bitset
+func newHeapAllocator(slots []*Slot, strategy Allocator) *slotAllocator {
+	allocator := newSlotAllocator(slots, ExactSize, strategy)
+	for _, pool := range allocator.pools {
+		pool.compact = nil
+		pool.emptySlot = qheap.New(slotKey.less)
+		pool.keys = make(map[int]slotKey)
+		pool.ranks = make([]int, len(pool.order))
+		for i, slot := range pool.order {
+			pool.ranks[i] = strategy.Rank(slot)
+		}
+	}
+	return allocator
+}
+
+// Check that pools with a compact bitset allocate the same slots as pools
+// with the emptySlot heap
+func TestSlotAllocatorCompact(t *testing.T) {
+	strategies := []struct {
+		name     string
+		strategy Allocator
+	}{
+		{name: "Nearest entry", strategy: nearestEntry{}},
+		{name: "Nearest exit", strategy: nearestExit{}},
+		{name: "Fill from back", strategy: fillFromBack{}},
+	}
+	for _, tt := range strategies {
+		t.Run(tt.name, func(t *testing.T) {
+			const capacity = 200
+			compactSlots := generateParkingSlot(capacity)
+			heapSlots := generateParkingSlot(capacity)
+			compact := newSlotAllocator(compactSlots, ExactSize, tt.strategy)
+			heap := newHeapAllocator(heapSlots, tt.strategy)
+			if compact.pools[0].compact == nil {
+				t.Fatalf("newSlotAllocator() did not make the pool compact")
+			}
+
+			r := rand.New(rand.NewSource(1))
+			var parked []int
+			for i := 0; i < 5000; i++ {
+				if len(parked) > 0 && r.Intn(2) == 0 {
+					j := r.Intn(len(parked))
+					compact.release(compactSlots[parked[j]-1])
+					heap.release(heapSlots[parked[j]-1])
+					parked = append(parked[:j], parked[j+1:]...)
+					continue
+				}
+				got, gotErr := compact.allocate(Car, 0)
+				want, wantErr := heap.allocate(Car, 0)
+				if !reflect.DeepEqual(got, want) || (gotErr != nil) != (wantErr != nil) {
+					t.Fatalf("allocate() got = %v, want = %v", got, want)
+				}
+				parked = append(parked, got...)
+				if compact.getFreeCount(0) != heap.getFreeCount(0) {
+					t.Fatalf("getFreeCount() got = %v, want = %v", compact.getFreeCount(0), heap.getFreeCount(0))
+				}
+			}
+			if got, want := len(compact.getUsedSlots()), len(heap.getUsedSlots()); got != want {
+				t.Errorf("getUsedSlots() got %v slots, want = %v", got, want)
+			}
+		})
+	}
+}
+
+const benchmarkCapacity = 1000000
+
+// Fill a very large lot, then keep releasing a random slot and allocating the
+// lowest free slot again
+func benchmarkSlotAllocator(b *testing.B, newAllocator func([]*Slot) *slotAllocator) {
+	slots := generateParkingSlot(benchmarkCapacity)
+	allocator := newAllocator(slots)
+	for i := 0; i < benchmarkCapacity; i++ {
+		allocator.allocate(Car, 0)
+	}
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		allocator.release(slots[r.Intn(benchmarkCapacity)])
+		allocator.allocate(Car, 0)
+	}
+}
+
+func BenchmarkSlotAllocatorHeap(b *testing.B) {
+	benchmarkSlotAllocator(b, func(slots []*Slot) *slotAllocator {
+		return newHeapAllocator(slots, nearestEntry{})
+	})
+}
+
+func BenchmarkSlotAllocatorCompact(b *testing.B) {
+	benchmarkSlotAllocator(b, func(slots []*Slot) *slotAllocator {
+		return newSlotAllocator(slots, ExactSize, nearestEntry{})
+	})
+}
+
+// Measure the memory held by the free slots of a very large lot once every
+// slot has been used and freed again
+func benchmarkFreedSlots(b *testing.B, newAllocator func([]*Slot) *slotAllocator) {
+	slots := generateParkingSlot(benchmarkCapacity)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		allocator := newAllocator(slots)
+		for j := 0; j < benchmarkCapacity; j++ {
+			allocator.allocate(Car, 0)
+		}
+		for _, slot := range slots {
+			allocator.release(slot)
+		}
+	}
+}
+
+func BenchmarkFreedSlotsHeap(b *testing.B) {
+	benchmarkFreedSlots(b, func(slots []*Slot) *slotAllocator {
+		return newHeapAllocator(slots, nearestEntry{})
+	})
+}
+
+func BenchmarkFreedSlotsCompact(b *testing.B) {
+	benchmarkFreedSlots(b, func(slots []*Slot) *slotAllocator {
+		return newSlotAllocator(slots, ExactSize, nearestEntry{})
+	})
+}
+
+// Fill a very large lot and free every other slot, then keep releasing a
+// random parked slot and allocating the lowest free slot again, so that the
+// free slots stay scattered across the lot
+func benchmarkFragmentedSlotAllocator(b *testing.B, newAllocator func([]*Slot) *slotAllocator) {
+	slots := generateParkingSlot(benchmarkCapacity)
+	allocator := newAllocator(slots)
+	parked := make([]bool, benchmarkCapacity)
+	for i := 0; i < benchmarkCapacity; i++ {
+		allocator.allocate(Car, 0)
+		parked[i] = true
+	}
+	for i := 0; i < benchmarkCapacity; i += 2 {
+		allocator.release(slots[i])
+		parked[i] = false
+	}
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := r.Intn(benchmarkCapacity)
+		for !parked[j] {
+			j = r.Intn(benchmarkCapacity)
+		}
+		allocator.release(slots[j])
+		parked[j] = false
+		slotNumbers, _ := allocator.allocate(Car, 0)
+		parked[slotNumbers[0]-1] = true
+	}
+}
+
+func BenchmarkFragmentedSlotAllocatorHeap(b *testing.B) {
+	benchmarkFragmentedSlotAllocator(b, func(slots []*Slot) *slotAllocator {
+		return newHeapAllocator(slots, nearestEntry{})
+	})
+}
+
+func BenchmarkFragmentedSlotAllocatorCompact(b *testing.B) {
+	benchmarkFragmentedSlotAllocator(b, func(slots []*Slot) *slotAllocator {
+		return newSlotAllocator(slots, ExactSize, nearestEntry{})
+	})
+}
+
+// Measure the memory a very large lot's allocator holds on to once every slot
+// has been used and freed again, per slot, apart from the slots themselves
+func benchmarkHeldMemory(b *testing.B, newAllocator func([]*Slot) *slotAllocator) {
+	slots := generateParkingSlot(benchmarkCapacity)
+	var held uint64
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		allocator := newAllocator(slots)
+		for j := 0; j < benchmarkCapacity; j++ {
+			allocator.allocate(Car, 0)
+		}
+		for _, slot := range slots {
+			allocator.release(slot)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(allocator)
+		held += after.HeapAlloc - before.HeapAlloc
+	}
+	b.ReportMetric(float64(held)/float64(b.N)/benchmarkCapacity, "held-B/slot")
+}
+
+func BenchmarkHeldMemoryHeap(b *testing.B) {
+	benchmarkHeldMemory(b, func(slots []*Slot) *slotAllocator {
+		return newHeapAllocator(slots, nearestEntry{})
+	})
+}
+
+func BenchmarkHeldMemoryCompact(b *testing.B) {
+	benchmarkHeldMemory(b, func(slots []*Slot) *slotAllocator {
+		return newSlotAllocator(slots, ExactSize, nearestEntry{})
+	})
+}
@@ -0,0 +1,79 @@
+package parkinglot_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cedrickchee/go-parkinglot/pkg/parkinglot"
+)
+
+// Drive a parking lot through the exported API only, as a service embedding
+// the package would
+func TestPublicAPI(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	clock := parkinglot.NewFakeClock(start)
+	lot := parkinglot.New(&parkinglot.Options{Clock: clock, Operator: "alice", Waitlist: true})
+
+	if _, err := lot.Park(parkinglot.NewVehicle("KA-01-HH-1234", "White", parkinglot.Car, 0)); !errors.Is(err, parkinglot.ErrNotCreated) {
+		t.Errorf("Park() before Create() error = %v, want = %v", err, parkinglot.ErrNotCreated)
+	}
+	if _, err := lot.SlotsWithAttributes(parkinglot.EVCharger); !errors.Is(err, parkinglot.ErrNotCreated) {
+		t.Errorf("SlotsWithAttributes() before Create() error = %v, want = %v", err, parkinglot.ErrNotCreated)
+	}
+
+	fillFromBack, err := parkinglot.LookupAllocator("fill_from_back")
+	if err != nil {
+		t.Fatalf("LookupAllocator() error = %v", err)
+	}
+	floors := parkinglot.StackedFloors([]int{2})
+	if err := lot.Create("Marina Bay Sands", floors, &parkinglot.CreateOptions{Allocator: fillFromBack}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := lot.Create("Marina Bay Sands", floors, nil); !errors.Is(err, parkinglot.ErrAlreadyCreated) {
+		t.Errorf("Create() twice error = %v, want = %v", err, parkinglot.ErrAlreadyCreated)
+	}
+	if lot.Capacity() != 2 || len(lot.Slots()) != 2 || len(lot.Floors()) != 1 {
+		t.Errorf("Capacity() got = %v, want = %v", lot.Capacity(), 2)
+	}
+
+	for i, want := range []int{2, 1} {
+		ticket, err := lot.Park(parkinglot.NewVehicle([]string{"KA-01-HH-1234", "KA-01-HH-9999"}[i], "White", parkinglot.Car, 0))
+		if err != nil {
+			t.Fatalf("Park() error = %v", err)
+		}
+		if got := ticket.Slots()[0].SlotNumber(); got != want {
+			t.Errorf("Park() got slot = %v, want = %v", got, want)
+		}
+	}
+	_, err = lot.Park(parkinglot.NewVehicle("KA-01-BB-0001", "Black", parkinglot.Car, 0))
+	var waiting *parkinglot.WaitingError
+	if !errors.As(err, &waiting) || waiting.Position != 1 {
+		t.Errorf("Park() in a full lot error = %v, want the vehicle on the waitlist", err)
+	}
+
+	clock.Advance(time.Hour)
+	ticket, err := lot.Leave(2)
+	if err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if !ticket.IsClosed() || ticket.Duration() != time.Hour {
+		t.Errorf("Leave() got ticket closed = %v, duration = %v", ticket.IsClosed(), ticket.Duration())
+	}
+	assigned := ticket.Assigned()
+	if len(assigned) != 1 || assigned[0].Vehicle().RegistrationNumber() != "KA-01-BB-0001" {
+		t.Errorf("Assigned() got = %v, want the waiting vehicle", assigned)
+	}
+
+	slotNumber, err := lot.SlotNumberForRegistrationNumber("KA-01-BB-0001")
+	if err != nil || slotNumber != 2 {
+		t.Errorf("SlotNumberForRegistrationNumber() got = %v, %v, want = %v", slotNumber, err, 2)
+	}
+	events, err := lot.HistoryForSlot(2)
+	if err != nil || len(events) != 3 || events[0].Operator() != "alice" {
+		t.Errorf("HistoryForSlot() got = %v, %v", events, err)
+	}
+	if got := len(lot.Status()); got != 2 {
+		t.Errorf("Status() got %v vehicles, want = %v", got, 2)
+	}
+}
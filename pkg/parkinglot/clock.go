@@ -0,0 +1,48 @@
+package parkinglot
+
+import (
+	"sync"
+	"time"
+)
+
+// A Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// The clock on the wall
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// An Advancer is a clock that scripts can move forward
+type Advancer interface {
+	Advance(d time.Duration)
+}
+
+// A FakeClock stands still until it is advanced, so that scripts and tests
+// can control the time. It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFakeClock returns a fake clock showing the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Advance moves the clock forward by a duration
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
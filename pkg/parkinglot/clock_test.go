@@ -0,0 +1,35 @@
+package parkinglot
+
+import (
+	"testing"
+	"time"
+)
+
+// Fixed time the tests run at
+var testTime = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(testTime)
+	pl := &ParkingLot{}
+	pl.setClock(clock)
+	if err := pl.createParkingLot("Marina Bay Sands", 2); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+
+	first, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", Car, 0))
+	if err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+	clock.Advance(2*time.Hour + 30*time.Minute)
+	second, err := pl.Park(NewVehicle("KA-01-HH-9999", "White", Car, 0))
+	if err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+
+	if got := first.Vehicle().EntryTime(); !got.Equal(testTime) {
+		t.Errorf("EntryTime() got = %v, want = %v", got, testTime)
+	}
+	if got, want := second.Vehicle().EntryTime(), testTime.Add(2*time.Hour+30*time.Minute); !got.Equal(want) {
+		t.Errorf("EntryTime() got = %v, want = %v", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package parkinglot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Colours known unless a colour file is given, with their aliases
+var DefaultColors = map[string][]string{
+	"Beige":  nil,
+	"Black":  nil,
+	"Blue":   {"Navy"},
+	"Brown":  nil,
+	"Gold":   nil,
+	"Green":  nil,
+	"Grey":   {"Gray"},
+	"Maroon": nil,
+	"Orange": nil,
+	"Purple": {"Violet"},
+	"Red":    nil,
+	"Silver": nil,
+	"White":  nil,
+	"Yellow": nil,
+}
+
+var defaultColorRegistry = NewColorRegistry(DefaultColors)
+
+// A ColorRegistry converts colour names to canonical form, ignoring case and
+// accepting aliases such as "Gray" for "Grey"
+type ColorRegistry struct {
+	names  map[string]string // Canonical name of each lower case name and alias
+	strict bool              // Reject unknown colours, instead of accepting them as they are
+}
+
+// Create a registry of colours with the aliases of each
+func NewColorRegistry(colors map[string][]string) *ColorRegistry {
+	r := &ColorRegistry{names: make(map[string]string)}
+	for color, aliases := range colors {
+		r.names[strings.ToLower(color)] = color
+		for _, alias := range aliases {
+			r.names[strings.ToLower(alias)] = color
+		}
+	}
+	return r
+}
+
+// Load a registry of colours from a JSON file mapping each canonical colour
+// name to a list of its aliases
+func LoadColorRegistry(path string) (*ColorRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var colors map[string][]string
+	if err := json.Unmarshal(data, &colors); err != nil {
+		return nil, fmt.Errorf("Invalid colours: %v", err)
+	}
+	return NewColorRegistry(colors), nil
+}
+
+// Reject unknown colours if strict, instead of accepting them as they are
+func (r *ColorRegistry) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// Returns the canonical name of a colour. Unknown colours are capitalized,
+// or rejected with a suggestion of the closest known colour if the registry
+// is strict.
+func (r *ColorRegistry) normalize(color string) (string, error) {
+	folded := strings.ToLower(strings.TrimSpace(color))
+	if name, ok := r.names[folded]; ok {
+		return name, nil
+	}
+	if folded == "" {
+		return "", fmt.Errorf("Unknown colour: %v", color)
+	}
+	if r.strict {
+		if suggestion := r.suggest(folded); suggestion != "" {
+			return "", fmt.Errorf("Unknown colour: %v, did you mean %v?", color, suggestion)
+		}
+		return "", fmt.Errorf("Unknown colour: %v", color)
+	}
+	first, size := utf8.DecodeRuneInString(folded)
+	return string(unicode.ToUpper(first)) + folded[size:], nil
+}
+
+// Get the known colour closest to a lower case colour name, or an empty
+// string if none is close
+func (r *ColorRegistry) suggest(folded string) string {
+	var names []string
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Break ties the same way every time
+
+	best, bestDistance := "", len(folded)/3+1
+	for _, name := range names {
+		if d := editDistance(folded, name); d < bestDistance {
+			best, bestDistance = r.names[name], d
+		}
+	}
+	return best
+}
+
+// Returns the number of single character insertions, deletions and
+// substitutions that turn one string into the other
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,100 @@
+package parkinglot
+
+import (
+	"testing"
+)
+
+func TestColorRegistryNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		strict  bool
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Known colour in lower case",
+			input: "white",
+			want:  "White",
+		},
+		{
+			name:  "Alias of a known colour",
+			input: "GRAY",
+			want:  "Grey",
+		},
+		{
+			name:  "Unknown colour is capitalized",
+			input: "teal",
+			want:  "Teal",
+		},
+		{
+			name:  "Unknown colour starting with a multibyte letter",
+			input: "éclair",
+			want:  "Éclair",
+		},
+		{
+			name:    "Unknown colour in strict mode",
+			strict:  true,
+			input:   "Whte",
+			wantErr: true,
+		},
+		{
+			name:   "Known colour in strict mode",
+			strict: true,
+			input:  "Navy",
+			want:   "Blue",
+		},
+		{
+			name:    "Empty colour",
+			input:   " ",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colors := NewColorRegistry(DefaultColors)
+			colors.SetStrict(tt.strict)
+			got, err := colors.normalize(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("normalize() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("normalize() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorRegistrySuggest(t *testing.T) {
+	colors := NewColorRegistry(DefaultColors)
+	colors.SetStrict(true)
+
+	_, err := colors.normalize("Blak")
+	if want := "Unknown colour: Blak, did you mean Black?"; err == nil || err.Error() != want {
+		t.Errorf("normalize() error = %v, want = %v", err, want)
+	}
+	_, err = colors.normalize("Fuchsia")
+	if want := "Unknown colour: Fuchsia"; err == nil || err.Error() != want {
+		t.Errorf("normalize() error = %v, want = %v", err, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "grey", b: "grey", want: 0},
+		{a: "grey", b: "gray", want: 1},
+		{a: "blak", b: "black", want: 1},
+		{a: "", b: "red", want: 3},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) got = %v, want = %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package parkinglot
+
+// A copier copies the slots, vehicles, tickets, permits and events the
+// exported methods return, so that callers never share state the lot goes on to change. Each
+// object is copied once, and the copies point to each other as the originals
+// do. It must be used with the lock held.
+type copier struct {
+	slots    map[*Slot]*Slot
+	vehicles map[*Vehicle]*Vehicle
+	tickets  map[*Ticket]*Ticket
+}
+
+func newCopier() *copier {
+	return &copier{
+		slots:    make(map[*Slot]*Slot),
+		vehicles: make(map[*Vehicle]*Vehicle),
+		tickets:  make(map[*Ticket]*Ticket),
+	}
+}
+
+func (c *copier) slot(s *Slot) *Slot {
+	if s == nil {
+		return nil
+	}
+	if cp, ok := c.slots[s]; ok {
+		return cp
+	}
+	cp := *s
+	c.slots[s] = &cp
+	cp.vehicle = c.vehicle(s.vehicle)
+	return &cp
+}
+
+func (c *copier) slotList(slots []*Slot) []*Slot {
+	if slots == nil {
+		return nil
+	}
+	cp := make([]*Slot, len(slots))
+	for i, slot := range slots {
+		cp[i] = c.slot(slot)
+	}
+	return cp
+}
+
+func (c *copier) vehicle(v *Vehicle) *Vehicle {
+	if v == nil {
+		return nil
+	}
+	if cp, ok := c.vehicles[v]; ok {
+		return cp
+	}
+	cp := *v
+	c.vehicles[v] = &cp
+	cp.slots = c.slotList(v.slots)
+	cp.ticket = c.ticket(v.ticket)
+	cp.permit = c.permit(v.permit)
+	return &cp
+}
+
+func (c *copier) ticket(t *Ticket) *Ticket {
+	if t == nil {
+		return nil
+	}
+	if cp, ok := c.tickets[t]; ok {
+		return cp
+	}
+	cp := *t
+	c.tickets[t] = &cp
+	cp.vehicle = c.vehicle(t.vehicle)
+	if t.assigned != nil {
+		cp.assigned = make([]*Ticket, len(t.assigned))
+		for i, assigned := range t.assigned {
+			cp.assigned[i] = c.ticket(assigned)
+		}
+	}
+	return &cp
+}
+
+func (c *copier) floor(f *Floor) *Floor {
+	cp := *f
+	cp.slots = c.slotList(f.slots)
+	return &cp
+}
+
+func (c *copier) override(o *Override) *Override {
+	cp := *o
+	cp.ticket = c.ticket(o.ticket)
+	return &cp
+}
+
+func (c *copier) waitEntry(e *WaitEntry) *WaitEntry {
+	cp := *e
+	cp.vehicle = c.vehicle(e.vehicle)
+	return &cp
+}
+
+func (c *copier) permit(p *Permit) *Permit {
+	if p == nil {
+		return nil
+	}
+	cp := *p
+	return &cp
+}
+
+func (c *copier) permitList(permits []*Permit) []*Permit {
+	if permits == nil {
+		return nil
+	}
+	cp := make([]*Permit, len(permits))
+	for i, permit := range permits {
+		cp[i] = c.permit(permit)
+	}
+	return cp
+}
+
+func (c *copier) event(e *Event) *Event {
+	if e == nil {
+		return nil
+	}
+	cp := *e
+	cp.slotNumbers = append([]int(nil), e.slotNumbers...)
+	return &cp
+}
+
+func (c *copier) eventList(events []*Event) []*Event {
+	if events == nil {
+		return nil
+	}
+	cp := make([]*Event, len(events))
+	for i, event := range events {
+		cp[i] = c.event(event)
+	}
+	return cp
+}
+
+// Reservations only point to slots by number, so they are copied on their own
+func copyReservation(r *Reservation) *Reservation {
+	if r == nil {
+		return nil
+	}
+	cp := *r
+	return &cp
+}
+
+// Returns a vehicle of its own for the lot to park, with the registration
+// number, colour, type and needs of the one a caller passed in
+func (v *Vehicle) clone() *Vehicle {
+	return NewVehicle(v.registrationNumber, v.color, v.vehicleType, v.needs)
+}
@@ -0,0 +1,165 @@
+package parkinglot
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by a parking lot. Most are wrapped in one of the error types
+// below with the slot, vehicle, gate, ticket or reservation they concern, so
+// compare them with errors.Is.
+var (
+	ErrNotCreated        = errors.New("parking lot is not created")
+	ErrAlreadyCreated    = errors.New("parking lot is already created")
+	ErrNoFloors          = errors.New("parking lot must have at least one floor")
+	ErrInvalidCapacity   = errors.New("floor capacity must be greater than zero")
+	ErrTooManySizes      = errors.New("number of slot sizes exceeds floor capacity")
+	ErrTooManyAttributes = errors.New("number of slot attributes exceeds floor capacity")
+
+	ErrGateExists    = errors.New("gate already exists")
+	ErrGateNotFound  = errors.New("gate not found")
+	ErrGateDistances = errors.New("number of gate distances does not match number of slots")
+
+	ErrParkingLotFull = errors.New("parking lot is full")
+	ErrNoFittingSlot  = errors.New("parking lot has no slot for the vehicle")
+	ErrAlreadyParked  = errors.New("vehicle is already parked")
+	ErrAlreadyWaiting = errors.New("vehicle is already waiting")
+	ErrWaitlisted     = errors.New("parking lot is full, vehicle is on the waitlist")
+	ErrNoReason       = errors.New("override needs a reason")
+	ErrNoWaitlist     = errors.New("parking lot has no waitlist")
+	ErrNotFound       = errors.New("not found")
+
+	ErrUnknownVehicleType = errors.New("unknown vehicle type")
+
+	ErrInvalidSlot       = errors.New("invalid slot number")
+	ErrSlotEmpty         = errors.New("vehicle is not found in slot")
+	ErrSlotOccupied      = errors.New("slot is occupied")
+	ErrSlotReserved      = errors.New("slot is reserved")
+	ErrSlotDedicated     = errors.New("slot is dedicated to a permit")
+	ErrVehicleDoesNotFit = errors.New("vehicle does not fit the slot")
+
+	ErrTicketNotFound = errors.New("ticket not found")
+	ErrTicketClosed   = errors.New("ticket is already closed")
+
+	ErrPermitExists       = errors.New("registration number already has a permit")
+	ErrInvalidPermitDates = errors.New("permit must end on or after the day it starts")
+	ErrNegativePermitPool = errors.New("permit pool size must not be negative")
+	ErrPermitPoolTooLarge = errors.New("permit pool is larger than the slots not dedicated to a permit")
+	ErrNegativeDays       = errors.New("number of days must not be negative")
+
+	ErrReservationSpan     = errors.New("only vehicles that fit in one slot can reserve")
+	ErrInvalidDuration     = errors.New("reservation must last longer than zero")
+	ErrStartInPast         = errors.New("reservation must not start in the past")
+	ErrReservationOverlaps = errors.New("registration number already has a reservation at that time")
+	ErrNoSlotFreeAtTime    = errors.New("no slot is free at that time")
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationInactive = errors.New("reservation is no longer active")
+
+	ErrSnapshotVersion   = errors.New("unsupported snapshot version")
+	ErrInvalidSnapshot   = errors.New("invalid snapshot")
+	ErrAllocatorNotSaved = errors.New("only parking lots with a built-in allocator can be saved")
+)
+
+// A SlotError is a failure concerning a slot
+type SlotError struct {
+	SlotNumber int
+	Err        error
+}
+
+func (e *SlotError) Error() string {
+	return fmt.Sprintf("slot %v: %v", e.SlotNumber, e.Err)
+}
+
+func (e *SlotError) Unwrap() error {
+	return e.Err
+}
+
+// A VehicleError is a failure concerning a registration number
+type VehicleError struct {
+	RegistrationNumber string
+	Err                error
+}
+
+func (e *VehicleError) Error() string {
+	return fmt.Sprintf("registration number %v: %v", e.RegistrationNumber, e.Err)
+}
+
+func (e *VehicleError) Unwrap() error {
+	return e.Err
+}
+
+// A GateError is a failure concerning an entry gate
+type GateError struct {
+	Gate string
+	Err  error
+}
+
+func (e *GateError) Error() string {
+	return fmt.Sprintf("gate %v: %v", e.Gate, e.Err)
+}
+
+func (e *GateError) Unwrap() error {
+	return e.Err
+}
+
+// A TicketError is a failure concerning a ticket
+type TicketError struct {
+	TicketNumber int
+	Err          error
+}
+
+func (e *TicketError) Error() string {
+	return fmt.Sprintf("ticket %v: %v", e.TicketNumber, e.Err)
+}
+
+func (e *TicketError) Unwrap() error {
+	return e.Err
+}
+
+// A ReservationError is a failure concerning a reservation, along with the
+// status the reservation had at the time if it exists
+type ReservationError struct {
+	ReservationNumber int
+	Status            ReservationStatus
+	Err               error
+}
+
+func (e *ReservationError) Error() string {
+	return fmt.Sprintf("reservation %v: %v", e.ReservationNumber, e.Err)
+}
+
+func (e *ReservationError) Unwrap() error {
+	return e.Err
+}
+
+// A FitError is returned when no slot of the parking lot fits a vehicle type
+// with the attributes it needs, whether or not the slots are free
+type FitError struct {
+	VehicleType VehicleType
+	Needs       Attributes
+}
+
+func (e *FitError) Error() string {
+	if e.Needs != 0 {
+		return fmt.Sprintf("parking lot has no %v slot for a %v", e.Needs, e.VehicleType)
+	}
+	return fmt.Sprintf("parking lot has no slot for a %v", e.VehicleType)
+}
+
+func (e *FitError) Unwrap() error {
+	return ErrNoFittingSlot
+}
+
+// A WaitingError is returned by Park when a full parking lot puts the vehicle
+// on the waitlist
+type WaitingError struct {
+	Position int // Position on the waitlist, from 1
+}
+
+func (e *WaitingError) Error() string {
+	return fmt.Sprintf("parking lot is full, waitlist position: %v", e.Position)
+}
+
+func (e *WaitingError) Unwrap() error {
+	return ErrWaitlisted
+}
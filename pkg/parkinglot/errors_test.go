@@ -0,0 +1,102 @@
+package parkinglot
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	uncreated := &ParkingLot{}
+	pl := &ParkingLot{}
+	pl.setClock(NewFakeClock(testTime))
+	if err := pl.createParkingLot("Marina Bay Sands", 2); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+	if _, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", Car, 0)); err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+	reservation, err := pl.Reserve("KA-01-HH-9999", Car, testTime.Add(time.Hour), time.Hour, 2)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		target     error
+		slotNumber int    // Slot number of the SlotError, if any
+		plate      string // Registration number of the VehicleError, if any
+	}{
+		{
+			name:   "Parking lot is not created",
+			err:    func() error { _, err := uncreated.Leave(1); return err }(),
+			target: ErrNotCreated,
+		},
+		{
+			name:       "Invalid slot number",
+			err:        func() error { _, err := pl.Leave(3); return err }(),
+			target:     ErrInvalidSlot,
+			slotNumber: 3,
+		},
+		{
+			name:       "Empty slot",
+			err:        func() error { _, err := pl.Leave(2); return err }(),
+			target:     ErrSlotEmpty,
+			slotNumber: 2,
+		},
+		{
+			name:   "Vehicle already parked",
+			err:    func() error { _, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", Car, 0)); return err }(),
+			target: ErrAlreadyParked,
+			plate:  "KA-01-HH-1234",
+		},
+		{
+			name:   "Registration number not found",
+			err:    func() error { _, err := pl.SlotNumberForRegistrationNumber("KA-01-HH-0000"); return err }(),
+			target: ErrNotFound,
+			plate:  "KA-01-HH-0000",
+		},
+		{
+			name:   "Ticket not found",
+			err:    func() error { _, err := pl.Ticket(7); return err }(),
+			target: ErrTicketNotFound,
+		},
+		{
+			name:   "No slot fits the vehicle",
+			err:    func() error { _, err := pl.Park(NewVehicle("KA-01-HH-0001", "White", Bus, 0)); return err }(),
+			target: ErrNoFittingSlot,
+		},
+		{
+			name: "Slot already reserved",
+			err: func() error {
+				_, err := pl.Reserve("KA-01-HH-0002", Car, testTime.Add(time.Hour), time.Hour, 2)
+				return err
+			}(),
+			target:     ErrSlotReserved,
+			slotNumber: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.target) {
+				t.Fatalf("error = %v, want = %v", tt.err, tt.target)
+			}
+			var slotErr *SlotError
+			if errors.As(tt.err, &slotErr) != (tt.slotNumber != 0) || (slotErr != nil && slotErr.SlotNumber != tt.slotNumber) {
+				t.Errorf("error = %v, want slot number %v", tt.err, tt.slotNumber)
+			}
+			var vehicleErr *VehicleError
+			if errors.As(tt.err, &vehicleErr) != (tt.plate != "") || (vehicleErr != nil && vehicleErr.RegistrationNumber != tt.plate) {
+				t.Errorf("error = %v, want registration number %q", tt.err, tt.plate)
+			}
+		})
+	}
+
+	// The reservation in the way is reported along with the slot
+	_, err = pl.Reserve("KA-01-HH-0002", Car, testTime.Add(time.Hour), time.Hour, 2)
+	var reservationErr *ReservationError
+	if !errors.As(err, &reservationErr) || reservationErr.ReservationNumber != reservation.ReservationNumber() {
+		t.Errorf("Reserve() error = %v, want reservation number %v", err, reservation.ReservationNumber())
+	}
+}
@@ -0,0 +1,31 @@
+package parkinglot
+
+// A Floor is a single storey of the parking lot. Each floor owns a
+// contiguous range of slot numbers.
+type Floor struct {
+	floorNumber int
+	distance    int // Distance from the entry point to the floor
+	slots       []*Slot
+}
+
+// A FloorLayout describes the slots to create on a single floor
+type FloorLayout struct {
+	Capacity     int
+	Distance     int          // Distance from the entry point to the floor
+	ExitDistance int          // Distance from the far end of the floor to the exit
+	Sizes        []SlotSize   // Size of each slot on the floor, medium if not given
+	Attributes   []Attributes // Attributes of each slot on the floor, none if not given
+}
+
+func (f *Floor) FloorNumber() int {
+	return f.floorNumber
+}
+
+// Returns the number of slots on the floor
+func (f *Floor) Capacity() int {
+	return len(f.slots)
+}
+
+func (f *Floor) Slots() []*Slot {
+	return f.slots
+}
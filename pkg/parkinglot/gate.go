@@ -0,0 +1,60 @@
+package parkinglot
+
+import (
+	qheap "github.com/cedrickchee/go-parkinglot/internal/heap"
+)
+
+// A gateQueue hands out the free slots of a pool nearest to one gate first
+type gateQueue struct {
+	slots     []*Slot // All slots, ordered by slot number
+	distances []int   // Distance from the gate to each slot, by slot number
+	emptySlot *qheap.PriorityQueue[int]
+}
+
+func newGateQueue(slots []*Slot, distances []int) *gateQueue {
+	q := &gateQueue{slots: slots, distances: distances}
+	q.emptySlot = qheap.New(func(a, b int) bool {
+		return q.getKey(slots[a-1]).less(q.getKey(slots[b-1]))
+	})
+	return q
+}
+
+// Returns the key of a free slot ranked by its distance from the gate
+func (q *gateQueue) getKey(slot *Slot) slotKey {
+	return newSlotKey(slot, q.distances[slot.SlotNumber()-1])
+}
+
+// Get the key of the free slot nearest to the gate without taking it
+func (q *gateQueue) peek() (slotKey, bool) {
+	slotNumber, ok := q.emptySlot.Peek()
+	if !ok {
+		return slotKey{}, false
+	}
+	return q.getKey(q.slots[slotNumber-1]), true
+}
+
+// Add a free slot to the queue
+func (q *gateQueue) push(slotNumber int) {
+	q.emptySlot.Push(slotNumber)
+}
+
+// Remove a slot taken through any gate or the allocation strategy
+func (q *gateQueue) remove(slotNumber int) {
+	q.emptySlot.Remove(slotNumber)
+}
+
+// Get the distance from a gate next to the given slot to each slot, by slot
+// number. Slots lie along a single road in order of their distance from the
+// entry point.
+func GateDistances(slots []*Slot, slotNumber int) []int {
+	anchor := slots[slotNumber-1].Distance()
+	distances := make([]int, len(slots))
+	for i, slot := range slots {
+		distance := slot.Distance() - anchor
+		if distance < 0 {
+			distance = -distance
+		}
+		distances[i] = distance + 1
+	}
+	return distances
+}
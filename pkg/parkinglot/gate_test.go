@@ -0,0 +1,102 @@
+package parkinglot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGateDistances(t *testing.T) {
+	slots := generateParkingSlot(5)
+	if got, want := GateDistances(slots, 4), []int{4, 3, 2, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GateDistances() got = %v, want = %v", got, want)
+	}
+}
+
+func TestParkAtGate(t *testing.T) {
+	pl := &ParkingLot{}
+	if err := pl.createParkingLot("Marina Bay Sands", 10); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+	for name, slotNumber := range map[string]int{"north": 1, "south": 10} {
+		if err := pl.AddGate(name, GateDistances(pl.slots, slotNumber)); err != nil {
+			t.Fatalf("AddGate() error = %v", err)
+		}
+	}
+	if err := pl.AddGate("north", GateDistances(pl.slots, 5)); err == nil {
+		t.Errorf("AddGate() error = %v, wantErr = %v", err, true)
+	}
+
+	tests := []struct {
+		name    string
+		gate    string // Park without a gate if empty
+		leave   int    // Slot number to leave before parking, if any
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Nearest slot to the south gate",
+			gate: "south",
+			want: 10,
+		},
+		{
+			name: "Nearest slot to the north gate",
+			gate: "north",
+			want: 1,
+		},
+		{
+			name: "Next nearest slot to the south gate",
+			gate: "south",
+			want: 9,
+		},
+		{
+			name: "Park without a gate",
+			want: 2,
+		},
+		{
+			name:  "Freed slot is nearest to the south gate again",
+			gate:  "south",
+			leave: 10,
+			want:  10,
+		},
+		{
+			name:  "Slot freed after parking without a gate",
+			gate:  "north",
+			leave: 2,
+			want:  2,
+		},
+		{
+			name:    "Gate not found",
+			gate:    "east",
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.leave > 0 {
+				if _, err := pl.Leave(tt.leave); err != nil {
+					t.Fatalf("Leave() error = %v", err)
+				}
+			}
+			vehicle := generateVehicle(i)
+			var ticket *Ticket
+			var err error
+			if tt.gate != "" {
+				ticket, err = pl.ParkAtGate(vehicle, tt.gate)
+			} else {
+				ticket, err = pl.Park(vehicle)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParkAtGate() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && ticket.Slots()[0].SlotNumber() != tt.want {
+				t.Errorf("ParkAtGate() slot = %v, want = %v", ticket.Slots()[0].SlotNumber(), tt.want)
+			}
+		})
+	}
+
+	if got, _ := pl.FreeSlotCount(0); got != 6 {
+		t.Errorf("FreeSlotCount() got = %v, want = %v", got, 6)
+	}
+}
@@ -0,0 +1,129 @@
+package parkinglot
+
+import (
+	"fmt"
+	"time"
+)
+
+// An EventType is something that happened in the parking lot
+type EventType int
+
+const (
+	ParkEvent  EventType = iota // A vehicle parked
+	LeaveEvent                  // A vehicle left
+)
+
+var eventTypeNames = []string{"park", "leave"}
+
+func (t EventType) String() string {
+	return eventTypeNames[t]
+}
+
+// Parse an event type name, such as "leave"
+func parseEventType(name string) (EventType, error) {
+	for i, typeName := range eventTypeNames {
+		if name == typeName {
+			return EventType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown event type: %v", name)
+}
+
+// An Event records a vehicle parking or leaving. Events are never changed
+// once recorded.
+type Event struct {
+	eventType          EventType
+	registrationNumber string
+	color              string
+	slotNumbers        []int // Slots the vehicle parked in or left
+	ticketNumber       int
+	time               time.Time
+	operator           string // Operator on duty, if known
+}
+
+func (e *Event) Type() EventType {
+	return e.eventType
+}
+
+func (e *Event) RegistrationNumber() string {
+	return e.registrationNumber
+}
+
+func (e *Event) Color() string {
+	return e.color
+}
+
+func (e *Event) SlotNumbers() []int {
+	return e.slotNumbers
+}
+
+func (e *Event) TicketNumber() int {
+	return e.ticketNumber
+}
+
+func (e *Event) Time() time.Time {
+	return e.time
+}
+
+func (e *Event) Operator() string {
+	return e.operator
+}
+
+// A history is the append-only log of events in the parking lot, indexed by
+// registration number and slot number. The zero value is an empty history.
+type history struct {
+	events         []*Event         // In the order they happened
+	byRegistration map[string][]int // Positions in events of the events of each registration number
+	bySlot         map[int][]int    // Positions in events of the events of each slot number
+}
+
+// Append an event to the history
+func (h *history) record(event *Event) {
+	if h.byRegistration == nil {
+		h.byRegistration = make(map[string][]int)
+		h.bySlot = make(map[int][]int)
+	}
+	i := len(h.events)
+	h.events = append(h.events, event)
+	h.byRegistration[event.registrationNumber] = append(h.byRegistration[event.registrationNumber], i)
+	for _, slotNumber := range event.slotNumbers {
+		h.bySlot[slotNumber] = append(h.bySlot[slotNumber], i)
+	}
+}
+
+// Get the events of a registration number, oldest first
+func (h *history) getByRegistrationNumber(registrationNumber string) []*Event {
+	return h.getEvents(h.byRegistration[registrationNumber])
+}
+
+// Get the events of a slot, oldest first
+func (h *history) getBySlot(slotNumber int) []*Event {
+	return h.getEvents(h.bySlot[slotNumber])
+}
+
+func (h *history) getEvents(positions []int) []*Event {
+	var events []*Event
+	for _, i := range positions {
+		events = append(events, h.events[i])
+	}
+	return events
+}
+
+// Get the park event of the vehicle that was in a slot at a point in time, or
+// nil if the slot was free. A vehicle is in the slot from the time it parks
+// until the time it leaves.
+func (h *history) getParkedAt(slotNumber int, t time.Time) *Event {
+	var parked *Event
+	for _, i := range h.bySlot[slotNumber] {
+		event := h.events[i]
+		if event.time.After(t) {
+			break
+		}
+		if event.eventType == ParkEvent {
+			parked = event
+		} else {
+			parked = nil
+		}
+	}
+	return parked
+}
@@ -0,0 +1,75 @@
+package parkinglot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryGetParkedAt(t *testing.T) {
+	var h history
+	h.record(&Event{eventType: ParkEvent, registrationNumber: "KA-01-HH-1234", slotNumbers: []int{1, 2}, time: testTime})
+	h.record(&Event{eventType: LeaveEvent, registrationNumber: "KA-01-HH-1234", slotNumbers: []int{1, 2}, time: testTime.Add(time.Hour)})
+	h.record(&Event{eventType: ParkEvent, registrationNumber: "KA-01-HH-9999", slotNumbers: []int{2}, time: testTime.Add(time.Hour)})
+
+	tests := []struct {
+		name       string
+		slotNumber int
+		time       time.Time
+		want       string // Registration number of the vehicle in the slot, empty if free
+	}{
+		{
+			name:       "Before any vehicle parked",
+			slotNumber: 1,
+			time:       testTime.Add(-time.Minute),
+			want:       "",
+		},
+		{
+			name:       "At the time the vehicle parked",
+			slotNumber: 1,
+			time:       testTime,
+			want:       "KA-01-HH-1234",
+		},
+		{
+			name:       "Second slot of a vehicle in several slots",
+			slotNumber: 2,
+			time:       testTime.Add(30 * time.Minute),
+			want:       "KA-01-HH-1234",
+		},
+		{
+			name:       "At the time the vehicle left",
+			slotNumber: 1,
+			time:       testTime.Add(time.Hour),
+			want:       "",
+		},
+		{
+			name:       "Next vehicle parked as the first one left",
+			slotNumber: 2,
+			time:       testTime.Add(time.Hour),
+			want:       "KA-01-HH-9999",
+		},
+		{
+			name:       "Slot without events",
+			slotNumber: 3,
+			time:       testTime,
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if event := h.getParkedAt(tt.slotNumber, tt.time); event != nil {
+				got = event.RegistrationNumber()
+			}
+			if got != tt.want {
+				t.Errorf("getParkedAt() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := len(h.getByRegistrationNumber("KA-01-HH-1234")); got != 2 {
+		t.Errorf("getByRegistrationNumber() got %v events, want %v", got, 2)
+	}
+	if got := len(h.getBySlot(2)); got != 3 {
+		t.Errorf("getBySlot() got %v events, want %v", got, 3)
+	}
+}
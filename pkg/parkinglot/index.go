@@ -0,0 +1,80 @@
+package parkinglot
+
+import (
+	"github.com/cedrickchee/go-parkinglot/internal/intervalset"
+)
+
+// A vehicleIndex finds parked vehicles by registration number and colour
+// without scanning the slots. Vehicles are indexed by the first slot they are
+// parked in.
+type vehicleIndex struct {
+	byRegistration map[string][]*Vehicle       // More than one only for vehicles parked by override
+	byColor        map[string]*intervalset.Set // First slot numbers of the vehicles of each colour
+}
+
+func newVehicleIndex() *vehicleIndex {
+	return &vehicleIndex{
+		byRegistration: make(map[string][]*Vehicle),
+		byColor:        make(map[string]*intervalset.Set),
+	}
+}
+
+// Add a vehicle once it is parked
+func (x *vehicleIndex) add(vehicle *Vehicle) {
+	x.byRegistration[vehicle.RegistrationNumber()] = append(x.byRegistration[vehicle.RegistrationNumber()], vehicle)
+
+	slots, ok := x.byColor[vehicle.Color()]
+	if !ok {
+		slots = &intervalset.Set{}
+		x.byColor[vehicle.Color()] = slots
+	}
+	slots.Add(vehicle.Slots()[0].SlotNumber())
+}
+
+// Remove a vehicle when it leaves
+func (x *vehicleIndex) remove(vehicle *Vehicle) {
+	vehicles := x.byRegistration[vehicle.RegistrationNumber()]
+	for i, v := range vehicles {
+		if v == vehicle {
+			vehicles = append(vehicles[:i:i], vehicles[i+1:]...)
+			break
+		}
+	}
+	if len(vehicles) == 0 {
+		delete(x.byRegistration, vehicle.RegistrationNumber())
+	} else {
+		x.byRegistration[vehicle.RegistrationNumber()] = vehicles
+	}
+
+	if slots, ok := x.byColor[vehicle.Color()]; ok {
+		slots.Remove(vehicle.Slots()[0].SlotNumber())
+		if len(slots.Intervals()) == 0 {
+			delete(x.byColor, vehicle.Color())
+		}
+	}
+}
+
+// Get the parked vehicles with a registration number
+func (x *vehicleIndex) getByRegistrationNumber(registrationNumber string) []*Vehicle {
+	if x == nil {
+		return nil
+	}
+	return x.byRegistration[registrationNumber]
+}
+
+// Get the first slot numbers of the parked vehicles of a colour, in
+// ascending order
+func (x *vehicleIndex) getSlotsByColor(color string) []int {
+	if x == nil {
+		return nil
+	}
+	var slotNumbers []int
+	if slots, ok := x.byColor[color]; ok {
+		for _, iv := range slots.Intervals() {
+			for n := iv.Start; n <= iv.End; n++ {
+				slotNumbers = append(slotNumbers, n)
+			}
+		}
+	}
+	return slotNumbers
+}
@@ -0,0 +1,96 @@
+package parkinglot
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestVehicleIndex(t *testing.T) {
+	pl := &ParkingLot{}
+	if err := pl.createParkingLot("Marina Bay Sands", 6); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+	for i, color := range []string{"White", "Black", "White", "White", "Black", "White"} {
+		if _, err := pl.Park(NewVehicle(fmt.Sprintf("KA-01-HH-%04d", i+1), color, Car, 0)); err != nil {
+			t.Fatalf("Park() error = %v", err)
+		}
+	}
+	for _, slotNumber := range []int{3, 5} {
+		if _, err := pl.Leave(slotNumber); err != nil {
+			t.Fatalf("Leave() error = %v", err)
+		}
+	}
+	if _, err := pl.Park(NewVehicle("KA-01-HH-0007", "Black", Car, 0)); err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+
+	gotSlots, gotRegisNumbers, err := pl.VehiclesByColor("White")
+	if err != nil {
+		t.Fatalf("VehiclesByColor() error = %v", err)
+	}
+	if want := []int{1, 4, 6}; !reflect.DeepEqual(gotSlots, want) {
+		t.Errorf("VehiclesByColor() gotSlots = %v, want = %v", gotSlots, want)
+	}
+	if want := []string{"KA-01-HH-0001", "KA-01-HH-0004", "KA-01-HH-0006"}; !reflect.DeepEqual(gotRegisNumbers, want) {
+		t.Errorf("VehiclesByColor() gotRegisNumbers = %v, want = %v", gotRegisNumbers, want)
+	}
+	if gotSlots, _, _ := pl.VehiclesByColor("Black"); !reflect.DeepEqual(gotSlots, []int{2, 3}) {
+		t.Errorf("VehiclesByColor() gotSlots = %v, want = %v", gotSlots, []int{2, 3})
+	}
+
+	if got, err := pl.SlotNumberForRegistrationNumber("KA-01-HH-0007"); err != nil || got != 3 {
+		t.Errorf("SlotNumberForRegistrationNumber() got = %v, %v, want = %v", got, err, 3)
+	}
+	if _, err := pl.SlotNumberForRegistrationNumber("KA-01-HH-0003"); err == nil {
+		t.Errorf("SlotNumberForRegistrationNumber() error = %v, wantErr = %v", err, true)
+	}
+}
+
+// Generate a full parking lot where one vehicle in every thousand is gold
+func generateFullParkingLot(b *testing.B, capacity int) *ParkingLot {
+	pl := &ParkingLot{}
+	if err := pl.createParkingLot("Marina Bay Sands", capacity); err != nil {
+		b.Fatalf("createParkingLot() error = %v", err)
+	}
+	colors := []string{"White", "Black", "Grey", "Red", "Blue"}
+	for i := 0; i < capacity; i++ {
+		color := colors[i%len(colors)]
+		if i%1000 == 0 {
+			color = "Gold"
+		}
+		if _, err := pl.Park(NewVehicle(fmt.Sprintf("KA-01-HH-%06d", i), color, Car, 0)); err != nil {
+			b.Fatalf("Park() error = %v", err)
+		}
+	}
+	return pl
+}
+
+func BenchmarkGetVehicleByRegistrationNumber(b *testing.B) {
+	for _, capacity := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprint(capacity), func(b *testing.B) {
+			pl := generateFullParkingLot(b, capacity)
+			registrationNumber := fmt.Sprintf("KA-01-HH-%06d", capacity-1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := pl.SlotNumberForRegistrationNumber(registrationNumber); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetVehiclesByColor(b *testing.B) {
+	for _, capacity := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprint(capacity), func(b *testing.B) {
+			pl := generateFullParkingLot(b, capacity)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := pl.VehiclesByColor("Gold"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
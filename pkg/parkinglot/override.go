@@ -0,0 +1,26 @@
+package parkinglot
+
+import (
+	"time"
+)
+
+// An Override records a vehicle parked by an administrator even though a
+// vehicle with the same registration number was already parked, such as a
+// cloned plate under investigation
+type Override struct {
+	ticket *Ticket
+	reason string
+	time   time.Time
+}
+
+func (o *Override) Ticket() *Ticket {
+	return o.ticket
+}
+
+func (o *Override) Reason() string {
+	return o.reason
+}
+
+func (o *Override) Time() time.Time {
+	return o.time
+}
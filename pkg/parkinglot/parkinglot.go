@@ -0,0 +1,1161 @@
+// This package implements the domain of a parking lot: floors of slots,
+// vehicles parking and leaving with tickets, and the permits, reservations,
+// waitlist and history around them. A lot is made by New and laid out by
+// Create before vehicles can park.
+package parkinglot
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A ParkingLot is safe for concurrent use by multiple gates. Parking, leaving
+// and every other change happen atomically, while reads only wait for changes
+// and not for each other. The slots, vehicles, tickets, reservations, permits
+// and events it returns are copies taken at the time of the call, which later
+// changes to the lot leave as they are, so any goroutine may read them.
+// Vehicles passed in to park are copied too.
+type ParkingLot struct {
+	mu sync.RWMutex // Held by the exported methods, the unexported ones expect it held
+
+	address      string
+	allocator    *slotAllocator
+	floors       []*Floor
+	slots        []*Slot          // All slots across floors, ordered by slot number
+	tickets      []*Ticket        // All tickets issued, ordered by ticket number
+	index        *vehicleIndex    // Parked vehicles by registration number and colour
+	history      history          // Every vehicle that parked or left
+	operator     string           // Operator on duty, recorded with every event
+	waitlist     *waitlist        // Vehicles waiting for a slot, nil if vehicles are turned away when full
+	serving      bool             // The waitlist is being served
+	permits      *permitRegistry  // Season permits and the slots reserved for them
+	reservations *reservationBook // Slots reserved ahead for a window of time
+	noShowAfter  time.Duration    // Time after the start of its window a reservation holds its slot, the default if zero
+	overrides    []*Override      // Log of vehicles parked by override, oldest first
+	tariff       *Tariff          // Tariff to charge vehicles by, vehicles park for free if nil
+	plates       PlateFormat      // Format of registration numbers, generic if nil
+	colors       *ColorRegistry   // Colours vehicles are matched by, the default colours if nil
+	clock        Clock            // Clock for entry and exit times, the system clock if nil
+	capacity     int              // Maximum slots available
+}
+
+// Options configure a parking lot. The zero value of each field gives the
+// default behaviour.
+type Options struct {
+	Clock       Clock          // Clock for entry and exit times, the system clock if nil
+	Operator    string         // Operator on duty, recorded with every event
+	PlateFormat PlateFormat    // Format of registration numbers, generic if nil
+	Colors      *ColorRegistry // Colours vehicles are matched by, the default colours if nil
+	Tariff      *Tariff        // Tariff to charge vehicles by, vehicles park for free if nil
+	Waitlist    bool           // Put vehicles on a waitlist when the parking lot is full
+	NoShowAfter time.Duration  // Time after the start of its window a reservation holds its slot, DefaultNoShowAfter if zero
+}
+
+// New returns a parking lot configured by the given options, or by the
+// defaults if opts is nil. The lot has no slots until it is laid out by Create.
+func New(opts *Options) *ParkingLot {
+	pl := &ParkingLot{}
+	if opts == nil {
+		return pl
+	}
+	pl.setClock(opts.Clock)
+	pl.SetOperator(opts.Operator)
+	pl.setPlateFormat(opts.PlateFormat)
+	pl.setColors(opts.Colors)
+	pl.setTariff(opts.Tariff)
+	pl.setNoShowAfter(opts.NoShowAfter)
+	if opts.Waitlist {
+		pl.enableWaitlist()
+	}
+	return pl
+}
+
+// CreateOptions choose how the slots of a parking lot are handed out. The zero
+// value of each field gives the default behaviour.
+type CreateOptions struct {
+	SizePolicy SizePolicy   // Slot sizes a vehicle may park in, ExactSize if not given
+	Allocator  NewAllocator // Allocation strategy, nearest to the entry point if nil
+}
+
+// Create lays out the floors of the parking lot at an address. Slots are
+// numbered consecutively starting from the first floor, from the entry end of
+// each floor to the exit end. A parking lot can only be created once.
+func (pl *ParkingLot) Create(address string, floors []FloorLayout, opts *CreateOptions) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if opts == nil {
+		opts = &CreateOptions{}
+	}
+	return pl.createMultiStoreyParkingLot(address, floors, opts.SizePolicy, opts.Allocator)
+}
+
+// Create a single floor parking lot of medium sized slots
+func (pl *ParkingLot) createParkingLot(address string, capacity int) error {
+	return pl.createMultiStoreyParkingLot(address, []FloorLayout{{Capacity: capacity}}, ExactSize, nil)
+}
+
+// Create a parking lot with one or more floors. Slots are numbered
+// consecutively starting from the first floor, from the entry end of the floor
+// to the exit end. Free slots are handed out nearest to the entry point first,
+// unless another allocator is given.
+func (pl *ParkingLot) createMultiStoreyParkingLot(address string, layouts []FloorLayout, policy SizePolicy, newAllocator NewAllocator) error {
+	if err := pl.isCreated(); err == nil {
+		return ErrAlreadyCreated
+	}
+	if len(layouts) == 0 {
+		return ErrNoFloors
+	}
+
+	var floors []*Floor
+	var slots []*Slot
+	for i, layout := range layouts {
+		if layout.Capacity <= 0 {
+			return ErrInvalidCapacity
+		}
+		if len(layout.Sizes) > layout.Capacity {
+			return ErrTooManySizes
+		}
+		if len(layout.Attributes) > layout.Capacity {
+			return ErrTooManyAttributes
+		}
+		floor := &Floor{floorNumber: i + 1, distance: layout.Distance}
+		for j := 0; j < layout.Capacity; j++ {
+			slot := &Slot{
+				slotNumber:   len(slots) + 1,
+				floorNumber:  floor.floorNumber,
+				distance:     layout.Distance + j + 1,
+				exitDistance: layout.ExitDistance + layout.Capacity - j,
+				size:         Medium,
+			}
+			if j < len(layout.Sizes) {
+				slot.size = layout.Sizes[j]
+			}
+			if j < len(layout.Attributes) {
+				slot.attributes = layout.Attributes[j]
+			}
+			floor.slots = append(floor.slots, slot)
+			slots = append(slots, slot)
+		}
+		floors = append(floors, floor)
+	}
+
+	pl.address = address
+	pl.capacity = len(slots)
+	pl.floors = floors
+	pl.slots = slots
+	pl.index = newVehicleIndex()
+	pl.permits = newPermitRegistry()
+	pl.reservations = newReservationBook()
+	if newAllocator == nil {
+		newAllocator = allocators[DefaultAllocator]
+	}
+	pl.allocator = newSlotAllocator(slots, policy, newAllocator(slots))
+
+	return nil
+}
+
+// Lay out floors stacked one above another, so that every floor is reached by
+// driving past all the slots on the floors below it, and left the same way.
+func StackedFloors(capacities []int) []FloorLayout {
+	var layouts []FloorLayout
+	distance := 0
+	for _, capacity := range capacities {
+		layouts = append(layouts, FloorLayout{Capacity: capacity, Distance: distance, ExitDistance: distance})
+		distance += capacity
+	}
+	return layouts
+}
+
+// Add an entry gate with the distance from it to each slot, by slot number
+func (pl *ParkingLot) AddGate(name string, distances []int) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return err
+	}
+	if _, ok := pl.allocator.getGate(name); ok {
+		return &GateError{Gate: name, Err: ErrGateExists}
+	}
+	if len(distances) != pl.capacity {
+		return &GateError{Gate: name, Err: ErrGateDistances}
+	}
+	pl.allocator.addGate(name, distances)
+	return nil
+}
+
+// Park a vehicle in the first free slots that fit it and have all the
+// attributes it needs. Returns the ticket issued to the vehicle.
+func (pl *ParkingLot) Park(vehicle *Vehicle) (*Ticket, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return copyTicket(pl.parkAtGate(vehicle.clone(), ""))
+}
+
+// Park a vehicle entering through a gate in the free slots nearest to the
+// gate that fit it and have all the attributes it needs. Vehicles entering
+// without a gate are parked as by Park. Permit holders park in their
+// dedicated slot if they have one. Returns the ticket issued to the vehicle.
+func (pl *ParkingLot) ParkAtGate(vehicle *Vehicle, gateName string) (*Ticket, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return copyTicket(pl.parkAtGate(vehicle.clone(), gateName))
+}
+
+func (pl *ParkingLot) parkAtGate(vehicle *Vehicle, gateName string) (*Ticket, error) {
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if err := pl.normalizeVehicle(vehicle); err != nil {
+		return nil, err
+	}
+	pl.checkPermit(vehicle)
+	pl.updateReservations()
+	if _, err := pl.slotNumberForRegistrationNumber(vehicle.RegistrationNumber()); err == nil {
+		return nil, &VehicleError{RegistrationNumber: vehicle.RegistrationNumber(), Err: ErrAlreadyParked}
+	}
+	if pl.waitlist != nil && pl.waitlist.contains(vehicle.RegistrationNumber()) {
+		return nil, &VehicleError{RegistrationNumber: vehicle.RegistrationNumber(), Err: ErrAlreadyWaiting}
+	}
+	// Waiting vehicles are served first, so the vehicle only gets a slot that
+	// none of them fits, and joins the end of the queue otherwise
+	pl.serveWaitlist()
+	slotNumbers, err := pl.allocate(vehicle, gateName)
+	if errors.Is(err, ErrParkingLotFull) && pl.waitlist != nil {
+		position := pl.waitlist.add(&WaitEntry{
+			vehicle:  vehicle,
+			gate:     gateName,
+			priority: vehicle.IsPermitHolder(),
+			since:    pl.Clock().Now(),
+		})
+		return nil, &WaitingError{Position: position}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pl.parkInSlots(vehicle, slotNumbers), nil
+}
+
+// Park a vehicle even if a vehicle with the same registration number is
+// already parked. Every override is logged with the reason given.
+func (pl *ParkingLot) OverridePark(vehicle *Vehicle, gateName string, reason string) (*Ticket, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return nil, ErrNoReason
+	}
+	vehicle = vehicle.clone()
+	if err := pl.normalizeVehicle(vehicle); err != nil {
+		return nil, err
+	}
+	pl.checkPermit(vehicle)
+	pl.updateReservations()
+	slotNumbers, err := pl.allocate(vehicle, gateName)
+	if err != nil {
+		return nil, err
+	}
+	ticket := pl.parkInSlots(vehicle, slotNumbers)
+	pl.overrides = append(pl.overrides, &Override{ticket: ticket, reason: reason, time: ticket.EntryTime()})
+	return newCopier().ticket(ticket), nil
+}
+
+// Validate the type, registration number and colour of a vehicle and convert
+// them to canonical form
+func (pl *ParkingLot) normalizeVehicle(vehicle *Vehicle) error {
+	if !vehicle.Type().isValid() {
+		return &VehicleError{RegistrationNumber: vehicle.RegistrationNumber(), Err: ErrUnknownVehicleType}
+	}
+	registrationNumber, err := pl.getPlateFormat().Normalize(vehicle.RegistrationNumber())
+	if err != nil {
+		return err
+	}
+	color, err := pl.getColors().normalize(vehicle.Color())
+	if err != nil {
+		return err
+	}
+	vehicle.registrationNumber = registrationNumber
+	vehicle.color = color
+	return nil
+}
+
+// Look up the permit of a vehicle valid now, after updating the dedicated
+// slots of permits
+func (pl *ParkingLot) checkPermit(vehicle *Vehicle) {
+	pl.updatePermits()
+	vehicle.permit = pl.permits.getValid(vehicle.RegistrationNumber(), pl.Clock().Now())
+}
+
+// Get the numbers of the slots to park a vehicle in, nearest to a gate if one
+// is given. Vehicles with a reservation park in the slot held for them.
+// Vehicles without a floating permit can not take the free slots still owed
+// to floating permit holders.
+func (pl *ParkingLot) allocate(vehicle *Vehicle, gateName string) ([]int, error) {
+	gate := noGate
+	if gateName != "" {
+		var ok bool
+		if gate, ok = pl.allocator.getGate(gateName); !ok {
+			return nil, &GateError{Gate: gateName, Err: ErrGateNotFound}
+		}
+	}
+
+	if reservation := pl.reservations.getHeldFor(vehicle.RegistrationNumber()); reservation != nil {
+		slot := pl.slots[reservation.SlotNumber()-1]
+		if pl.allocator.fits(slot, vehicle.Type(), vehicle.Needs()) {
+			return []int{slot.SlotNumber()}, nil
+		}
+	}
+	permit := vehicle.Permit()
+	if permit != nil && !permit.IsFloating() {
+		slot := pl.slots[permit.SlotNumber()-1]
+		if pl.permits.getDedicated(slot.SlotNumber()) == permit && slot.Vehicle() == nil &&
+			pl.allocator.fits(slot, vehicle.Type(), vehicle.Needs()) {
+			return []int{slot.SlotNumber()}, nil
+		}
+	}
+	if permit == nil || !permit.IsFloating() {
+		// The reserved slots are kept free in every size the vehicle fits, as
+		// the vehicles of the permit holders may need any of them
+		reserved := pl.permits.getReservedCount(pl.Clock().Now(), pl.isParked)
+		if reserved > 0 && pl.allocator.getFitCount(vehicle.Type()) < vehicle.Type().getSpan()+reserved {
+			return nil, ErrParkingLotFull
+		}
+	}
+
+	return pl.allocator.allocateAtGate(vehicle.Type(), vehicle.Needs(), gate)
+}
+
+// Reports whether a vehicle with a registration number in canonical form is parked
+func (pl *ParkingLot) isParked(registrationNumber string) bool {
+	return len(pl.index.getByRegistrationNumber(registrationNumber)) > 0
+}
+
+// Park a vehicle in the given slots and issue it a ticket
+func (pl *ParkingLot) parkInSlots(vehicle *Vehicle, slotNumbers []int) *Ticket {
+	for _, slotNumber := range slotNumbers {
+		pl.slots[slotNumber-1].parkVehicle(vehicle)
+	}
+
+	entryTime := pl.Clock().Now()
+	vehicle.entryTime = entryTime
+	ticket := issueTicket(len(pl.tickets)+1, vehicle, entryTime)
+	vehicle.ticket = ticket
+	pl.tickets = append(pl.tickets, ticket)
+	pl.index.add(vehicle)
+	pl.recordEvent(ParkEvent, vehicle, entryTime)
+	if reservation := pl.reservations.getHeldFor(vehicle.RegistrationNumber()); reservation != nil {
+		// A vehicle that does not fit its held slot parks elsewhere
+		pl.reservations.setStatus(reservation, Fulfilled)
+		if slotNumber := reservation.SlotNumber(); slotNumber != slotNumbers[0] {
+			pl.allocator.release(pl.slots[slotNumber-1])
+			pl.serveWaitlist()
+		}
+	}
+
+	return ticket
+}
+
+// Remove vehicle from parking slot, along with every other slot the vehicle
+// is parked in. Returns the closed ticket of the vehicle.
+func (pl *ParkingLot) Leave(slotNumber int) (*Ticket, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return copyTicket(pl.leave(slotNumber))
+}
+
+// Copy a ticket for an exported method to return, unless there is an error
+func copyTicket(ticket *Ticket, err error) (*Ticket, error) {
+	if err != nil {
+		return nil, err
+	}
+	return newCopier().ticket(ticket), nil
+}
+
+func (pl *ParkingLot) leave(slotNumber int) (*Ticket, error) {
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if slotNumber <= 0 || slotNumber > pl.capacity {
+		return nil, &SlotError{SlotNumber: slotNumber, Err: ErrInvalidSlot}
+	}
+
+	vehicle := pl.slots[slotNumber-1].Vehicle()
+	if vehicle != nil {
+		assigned := pl.updatePermits()
+		pl.index.remove(vehicle)
+		for _, slot := range vehicle.Slots() {
+			// Remove vehicle from slot
+			slot.removeVehicle()
+			// Make the slot available again, unless it is dedicated to a
+			// valid permit
+			if permit := pl.permits.getDedicated(slot.SlotNumber()); permit != nil && permit.IsValidAt(pl.Clock().Now()) {
+				pl.permits.hold(slot.SlotNumber())
+			} else {
+				pl.allocator.release(slot)
+			}
+		}
+
+		ticket := vehicle.Ticket()
+		exitTime := pl.Clock().Now()
+		var fee int64
+		if pl.tariff != nil {
+			fee = pl.tariff.getFee(vehicle.Type(), ticket.EntryTime(), exitTime)
+		}
+		ticket.close(exitTime, fee)
+		pl.recordEvent(LeaveEvent, vehicle, exitTime)
+		assigned = append(assigned, pl.updateReservations()...)
+		ticket.assigned = append(assigned, pl.serveWaitlist()...)
+		return ticket, nil
+	}
+
+	return nil, &SlotError{SlotNumber: slotNumber, Err: ErrSlotEmpty}
+}
+
+// Record a vehicle parking or leaving in the history
+func (pl *ParkingLot) recordEvent(eventType EventType, vehicle *Vehicle, t time.Time) {
+	var slotNumbers []int
+	for _, slot := range vehicle.Slots() {
+		slotNumbers = append(slotNumbers, slot.SlotNumber())
+	}
+	pl.history.record(&Event{
+		eventType:          eventType,
+		registrationNumber: vehicle.RegistrationNumber(),
+		color:              vehicle.Color(),
+		slotNumbers:        slotNumbers,
+		ticketNumber:       vehicle.Ticket().TicketNumber(),
+		time:               t,
+		operator:           pl.operator,
+	})
+}
+
+// Park waiting vehicles in the free slots, in the order they are served.
+// Vehicles that fit none of the free slots keep their place. Called whenever
+// slots are freed, so that no waiting vehicle fits a free slot in between.
+// Slots freed while the waitlist is served, by a vehicle that does not fit
+// its held slot, are served by the same call. Returns the tickets issued.
+func (pl *ParkingLot) serveWaitlist() []*Ticket {
+	if pl.waitlist == nil || pl.serving {
+		return nil
+	}
+	pl.serving = true
+	defer func() { pl.serving = false }()
+
+	var tickets []*Ticket
+	for served := true; served; {
+		served = false
+		for _, entry := range pl.waitlist.getEntries() {
+			if pl.allocator.getFreeCount(0) == 0 {
+				return tickets
+			}
+			slotNumbers, err := pl.allocate(entry.Vehicle(), entry.gate)
+			if err != nil {
+				continue
+			}
+			pl.waitlist.remove(entry.Vehicle().RegistrationNumber())
+			tickets = append(tickets, pl.parkInSlots(entry.Vehicle(), slotNumbers))
+			served = true
+		}
+	}
+	return tickets
+}
+
+// Given a vehicle registration number, take the vehicle off the waitlist
+func (pl *ParkingLot) CancelWait(registrationNumber string) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if pl.waitlist == nil {
+		return ErrNoWaitlist
+	}
+	registrationNumber, err := pl.getPlateFormat().Normalize(registrationNumber)
+	if err != nil {
+		return err
+	}
+	if !pl.waitlist.remove(registrationNumber) {
+		return &VehicleError{RegistrationNumber: registrationNumber, Err: ErrNotFound}
+	}
+	return nil
+}
+
+// Get the waiting vehicles in the order they are served
+func (pl *ParkingLot) Waitlist() ([]*WaitEntry, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if pl.waitlist == nil {
+		return nil, ErrNoWaitlist
+	}
+	c := newCopier()
+	var entries []*WaitEntry
+	for _, entry := range pl.waitlist.getEntries() {
+		entries = append(entries, c.waitEntry(entry))
+	}
+	return entries, nil
+}
+
+// Put vehicles on a waitlist when the parking lot is full, instead of
+// turning them away
+func (pl *ParkingLot) enableWaitlist() {
+	if pl.waitlist == nil {
+		pl.waitlist = &waitlist{}
+	}
+}
+
+// Add a permit for a registration number, valid from the start of the first
+// day to the end of the last day. The permit holds a dedicated slot if a slot
+// number is given, from the time it becomes valid, and the slot must be free
+// if that is now. A slot still occupied then is held once it is freed.
+// Otherwise the permit shares the floating pool. A permit replaces an expired
+// permit of the same registration number.
+func (pl *ParkingLot) AddPermit(registrationNumber string, validFrom, validUntil time.Time, slotNumber int) (*Permit, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	registrationNumber, err := pl.getPlateFormat().Normalize(registrationNumber)
+	if err != nil {
+		return nil, err
+	}
+	if validUntil.Before(validFrom) {
+		return nil, ErrInvalidPermitDates
+	}
+	pl.updatePermits()
+	now := pl.Clock().Now()
+	if permit, ok := pl.permits.get(registrationNumber); ok && !permit.IsExpiredAt(now) {
+		return nil, &VehicleError{RegistrationNumber: registrationNumber, Err: ErrPermitExists}
+	}
+	if slotNumber != 0 {
+		if slotNumber < 0 || slotNumber > pl.capacity {
+			return nil, &SlotError{SlotNumber: slotNumber, Err: ErrInvalidSlot}
+		}
+		if pl.permits.getDedicated(slotNumber) != nil {
+			return nil, &SlotError{SlotNumber: slotNumber, Err: ErrSlotDedicated}
+		}
+		if pl.reservations.isReserved(slotNumber) {
+			return nil, &SlotError{SlotNumber: slotNumber, Err: ErrSlotReserved}
+		}
+	}
+
+	permit := createPermit(registrationNumber, validFrom, validUntil, slotNumber)
+	if slotNumber != 0 && permit.IsValidAt(now) && pl.slots[slotNumber-1].Vehicle() != nil {
+		return nil, &SlotError{SlotNumber: slotNumber, Err: ErrSlotOccupied}
+	}
+	pl.permits.remove(registrationNumber)
+	pl.permits.add(permit)
+	pl.updatePermits()
+	return newCopier().permit(permit), nil
+}
+
+// Given a registration number, remove its permit and release its dedicated
+// slot, if it has one. A dedicated slot that is occupied is released when the
+// vehicle leaves.
+func (pl *ParkingLot) RevokePermit(registrationNumber string) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return err
+	}
+	registrationNumber, err := pl.getPlateFormat().Normalize(registrationNumber)
+	if err != nil {
+		return err
+	}
+	pl.updatePermits()
+	permit, ok := pl.permits.get(registrationNumber)
+	if !ok {
+		return &VehicleError{RegistrationNumber: registrationNumber, Err: ErrNotFound}
+	}
+	held := !permit.IsFloating() && pl.permits.getDedicated(permit.SlotNumber()) == permit && pl.permits.isHeld(permit.SlotNumber())
+	pl.permits.remove(registrationNumber)
+	if slot := pl.getSlot(permit.SlotNumber()); held && slot.Vehicle() == nil {
+		pl.allocator.release(slot)
+		pl.serveWaitlist()
+	}
+	return nil
+}
+
+// Hold the dedicated slots of permits that have become valid, and release
+// those of expired permits. Occupied slots are held or released when the
+// vehicle leaves. Returns the tickets issued to waiting vehicles given the
+// released slots.
+func (pl *ParkingLot) updatePermits() []*Ticket {
+	now := pl.Clock().Now()
+	released := false
+	for _, slotNumber := range pl.permits.expire(now) {
+		if slot := pl.slots[slotNumber-1]; slot.Vehicle() == nil {
+			pl.allocator.release(slot)
+			released = true
+		}
+	}
+	for _, slotNumber := range pl.permits.getUnheld(now) {
+		if pl.allocator.take(pl.slots[slotNumber-1]) {
+			pl.permits.hold(slotNumber)
+		}
+	}
+	if !released {
+		return nil
+	}
+	return pl.serveWaitlist()
+}
+
+// Reserve a number of slots for the floating permits to share
+func (pl *ParkingLot) SetPermitPool(size int) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return err
+	}
+	if size < 0 {
+		return ErrNegativePermitPool
+	}
+	pl.updatePermits()
+	if size > pl.capacity-len(pl.permits.dedicated) {
+		return ErrPermitPoolTooLarge
+	}
+	pl.permits.pool = size
+	// A smaller pool leaves more slots to vehicles without a permit
+	pl.serveWaitlist()
+	return nil
+}
+
+// Get every permit, ordered by registration number
+func (pl *ParkingLot) Permits() ([]*Permit, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	return newCopier().permitList(pl.permits.getPermits()), nil
+}
+
+// Get the permits that expire within a number of days, including those
+// already expired, soonest to expire first
+func (pl *ParkingLot) ExpiringPermits(days int) ([]*Permit, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if days < 0 {
+		return nil, ErrNegativeDays
+	}
+	return newCopier().permitList(pl.permits.getExpiring(pl.Clock().Now(), days)), nil
+}
+
+// Reserve a slot for a vehicle over a window of time. The slot is held from the
+// start of the window until the vehicle arrives, or until the no-show time has
+// passed. If no slot number is given, the lowest ranked slot that fits the
+// vehicle and is not reserved over the window is chosen, preferring slots
+// free now.
+func (pl *ParkingLot) Reserve(registrationNumber string, vehicleType VehicleType, start time.Time, duration time.Duration, slotNumber int) (*Reservation, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	registrationNumber, err := pl.getPlateFormat().Normalize(registrationNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !vehicleType.isValid() {
+		return nil, &VehicleError{RegistrationNumber: registrationNumber, Err: ErrUnknownVehicleType}
+	}
+	if vehicleType.getSpan() != 1 {
+		return nil, ErrReservationSpan
+	}
+	if duration <= 0 {
+		return nil, ErrInvalidDuration
+	}
+	if start.Before(pl.Clock().Now()) {
+		return nil, ErrStartInPast
+	}
+	pl.updatePermits()
+	pl.updateReservations()
+	end := start.Add(duration)
+	if other := pl.reservations.getOverlapping(registrationNumber, start, end); other != nil {
+		return nil, &VehicleError{
+			RegistrationNumber: registrationNumber,
+			Err:                &ReservationError{ReservationNumber: other.ReservationNumber(), Status: other.Status(), Err: ErrReservationOverlaps},
+		}
+	}
+
+	var slot *Slot
+	if slotNumber != 0 {
+		if slot = pl.getSlot(slotNumber); slot == nil {
+			return nil, &SlotError{SlotNumber: slotNumber, Err: ErrInvalidSlot}
+		}
+		if pl.permits.getDedicated(slotNumber) != nil {
+			return nil, &SlotError{SlotNumber: slotNumber, Err: ErrSlotDedicated}
+		}
+		if !pl.allocator.fits(slot, vehicleType, 0) {
+			return nil, &SlotError{SlotNumber: slotNumber, Err: ErrVehicleDoesNotFit}
+		}
+		if other := pl.reservations.getConflict(slotNumber, start, end); other != nil {
+			return nil, &SlotError{
+				SlotNumber: slotNumber,
+				Err:        &ReservationError{ReservationNumber: other.ReservationNumber(), Status: other.Status(), Err: ErrSlotReserved},
+			}
+		}
+	} else {
+		var best *Slot
+		var bestFree bool
+		var bestRank int
+		for _, candidate := range pl.slots {
+			n := candidate.SlotNumber()
+			if pl.permits.getDedicated(n) != nil || !pl.allocator.fits(candidate, vehicleType, 0) ||
+				pl.reservations.getConflict(n, start, end) != nil {
+				continue
+			}
+			free := candidate.Vehicle() == nil
+			rank := pl.allocator.strategy.Rank(candidate)
+			if best == nil || (free && !bestFree) || (free == bestFree && rank < bestRank) {
+				best, bestFree, bestRank = candidate, free, rank
+			}
+		}
+		if best == nil {
+			return nil, ErrNoSlotFreeAtTime
+		}
+		slot = best
+	}
+
+	reservation := &Reservation{
+		registrationNumber: registrationNumber,
+		slotNumber:         slot.SlotNumber(),
+		start:              start,
+		end:                end,
+	}
+	pl.reservations.add(reservation)
+	pl.updateReservations()
+	return copyReservation(reservation), nil
+}
+
+// Hold the slots of reservations whose window has started, and release the
+// slots of vehicles that have not arrived by the no-show time. A slot still
+// occupied when its window starts is held once it is freed. Returns the
+// tickets issued to waiting vehicles given the released slots.
+func (pl *ParkingLot) updateReservations() []*Ticket {
+	released := false
+	now := pl.Clock().Now()
+	for _, reservation := range pl.reservations.getStarted(now) {
+		slot := pl.slots[reservation.SlotNumber()-1]
+		switch {
+		case reservation.Status() == Pending && pl.isParked(reservation.RegistrationNumber()):
+			// The vehicle arrived before the window started
+			pl.reservations.setStatus(reservation, Fulfilled)
+		case !now.Before(reservation.Start().Add(pl.getNoShowAfter())):
+			if reservation.Status() == Held {
+				pl.allocator.release(slot)
+				released = true
+			}
+			pl.reservations.setStatus(reservation, NoShow)
+		case reservation.Status() == Pending && pl.allocator.take(slot):
+			pl.reservations.setStatus(reservation, Held)
+		}
+	}
+	if !released {
+		return nil
+	}
+	return pl.serveWaitlist()
+}
+
+// Given a reservation number, cancel the reservation and release its slot if
+// it is held
+func (pl *ParkingLot) CancelReservation(reservationNumber int) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return err
+	}
+	pl.updateReservations()
+	reservation := pl.reservations.get(reservationNumber)
+	if reservation == nil {
+		return &ReservationError{ReservationNumber: reservationNumber, Err: ErrReservationNotFound}
+	}
+	if !reservation.Status().isActive() {
+		return &ReservationError{ReservationNumber: reservationNumber, Status: reservation.Status(), Err: ErrReservationInactive}
+	}
+	held := reservation.Status() == Held
+	pl.reservations.setStatus(reservation, Cancelled)
+	if held {
+		pl.allocator.release(pl.slots[reservation.SlotNumber()-1])
+		pl.serveWaitlist()
+	}
+	return nil
+}
+
+// Get every reservation, ordered by reservation number
+func (pl *ParkingLot) Reservations() ([]*Reservation, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	pl.updateReservations()
+	var reservations []*Reservation
+	for _, reservation := range pl.reservations.reservations {
+		reservations = append(reservations, copyReservation(reservation))
+	}
+	return reservations, nil
+}
+
+// Set how long after the start of its window a reservation holds its slot
+func (pl *ParkingLot) setNoShowAfter(d time.Duration) {
+	pl.noShowAfter = d
+}
+
+func (pl *ParkingLot) getNoShowAfter() time.Duration {
+	if pl.noShowAfter == 0 {
+		return DefaultNoShowAfter
+	}
+	return pl.noShowAfter
+}
+
+// Remove the vehicle a ticket was issued to. Returns the closed ticket.
+func (pl *ParkingLot) LeaveByTicket(ticketNumber int) (*Ticket, error) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	ticket, err := pl.getTicket(ticketNumber)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.IsClosed() {
+		return nil, &TicketError{TicketNumber: ticketNumber, Err: ErrTicketClosed}
+	}
+
+	return copyTicket(pl.leave(ticket.Slots()[0].SlotNumber()))
+}
+
+// Given a ticket number, get the ticket whether it is open or closed
+func (pl *ParkingLot) Ticket(ticketNumber int) (*Ticket, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return copyTicket(pl.getTicket(ticketNumber))
+}
+
+func (pl *ParkingLot) getTicket(ticketNumber int) (*Ticket, error) {
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if ticketNumber <= 0 || ticketNumber > len(pl.tickets) {
+		return nil, &TicketError{TicketNumber: ticketNumber, Err: ErrTicketNotFound}
+	}
+
+	return pl.tickets[ticketNumber-1], nil
+}
+
+// Get a list of vehicles parked in the parking lot, ordered by slot number.
+// A vehicle parked in several slots is listed by the first of them.
+func (pl *ParkingLot) Status() []*Slot {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil
+	}
+
+	return newCopier().slotList(pl.getOccupiedSlots())
+}
+
+// Given a vehicle color or any of its aliases in any case, get the vehicle
+// slot and registration numbers
+func (pl *ParkingLot) VehiclesByColor(color string) ([]int, []string, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, nil, err
+	}
+
+	var slots []int
+	var regisNumbers []string
+
+	color, err := pl.getColors().normalize(color)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, slotNumber := range pl.index.getSlotsByColor(color) {
+		slots = append(slots, slotNumber)
+		regisNumbers = append(regisNumbers, pl.slots[slotNumber-1].Vehicle().RegistrationNumber())
+	}
+
+	if slots == nil {
+		return nil, nil, ErrNotFound
+	}
+
+	return slots, regisNumbers, nil
+}
+
+// Given a vehicle registration number in any form the plate format accepts,
+// get the vehicle slot number
+func (pl *ParkingLot) SlotNumberForRegistrationNumber(registrationNumber string) (int, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return 0, err
+	}
+	return pl.slotNumberForRegistrationNumber(registrationNumber)
+}
+
+func (pl *ParkingLot) slotNumberForRegistrationNumber(registrationNumber string) (int, error) {
+	registrationNumber, err := pl.getPlateFormat().Normalize(registrationNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	// Vehicles parked by override share a registration number, the one in the
+	// lowest slot number is found
+	slotNumber := 0
+	for _, vehicle := range pl.index.getByRegistrationNumber(registrationNumber) {
+		if n := vehicle.Slots()[0].SlotNumber(); slotNumber == 0 || n < slotNumber {
+			slotNumber = n
+		}
+	}
+	if slotNumber == 0 {
+		return 0, &VehicleError{RegistrationNumber: registrationNumber, Err: ErrNotFound}
+	}
+
+	return slotNumber, nil
+}
+
+// Given a vehicle registration number in any form the plate format accepts,
+// get the events of every vehicle with it, oldest first
+func (pl *ParkingLot) HistoryForRegistrationNumber(registrationNumber string) ([]*Event, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	registrationNumber, err := pl.getPlateFormat().Normalize(registrationNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	events := pl.history.getByRegistrationNumber(registrationNumber)
+	if events == nil {
+		return nil, &VehicleError{RegistrationNumber: registrationNumber, Err: ErrNotFound}
+	}
+
+	return newCopier().eventList(events), nil
+}
+
+// Given a slot number, get the events of every vehicle that parked in it,
+// oldest first
+func (pl *ParkingLot) HistoryForSlot(slotNumber int) ([]*Event, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if slotNumber <= 0 || slotNumber > pl.capacity {
+		return nil, &SlotError{SlotNumber: slotNumber, Err: ErrInvalidSlot}
+	}
+
+	events := pl.history.getBySlot(slotNumber)
+	if events == nil {
+		return nil, &SlotError{SlotNumber: slotNumber, Err: ErrNotFound}
+	}
+
+	return newCopier().eventList(events), nil
+}
+
+// Given a slot number and a point in time, get the park event of the vehicle
+// that was in the slot at that time
+func (pl *ParkingLot) VehicleInSlotAt(slotNumber int, t time.Time) (*Event, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+	if slotNumber <= 0 || slotNumber > pl.capacity {
+		return nil, &SlotError{SlotNumber: slotNumber, Err: ErrInvalidSlot}
+	}
+
+	event := pl.history.getParkedAt(slotNumber, t)
+	if event == nil {
+		return nil, &SlotError{SlotNumber: slotNumber, Err: ErrNotFound}
+	}
+
+	return newCopier().event(event), nil
+}
+
+// Given slot attributes, get the numbers of the slots that have all of them
+func (pl *ParkingLot) SlotsWithAttributes(attributes Attributes) ([]int, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return nil, err
+	}
+
+	var slots []int
+
+	for _, slot := range pl.slots {
+		if slot.Attributes().has(attributes) {
+			slots = append(slots, slot.SlotNumber())
+		}
+	}
+
+	if slots == nil {
+		return nil, ErrNotFound
+	}
+
+	return slots, nil
+}
+
+// Given slot attributes, get the number of free slots that have all of them
+func (pl *ParkingLot) FreeSlotCount(attributes Attributes) (int, error) {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	if err := pl.isCreated(); err != nil {
+		return 0, err
+	}
+	return pl.allocator.getFreeCount(attributes), nil
+}
+
+// Get a slot by its number
+func (pl *ParkingLot) Slot(slotNumber int) *Slot {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return newCopier().slot(pl.getSlot(slotNumber))
+}
+
+func (pl *ParkingLot) getSlot(slotNumber int) *Slot {
+	if slotNumber <= 0 || slotNumber > len(pl.slots) {
+		return nil
+	}
+	return pl.slots[slotNumber-1]
+}
+
+// Set the tariff to charge vehicles by when they leave
+func (pl *ParkingLot) setTariff(tariff *Tariff) {
+	pl.tariff = tariff
+}
+
+// The tariff and clock are only set by New, so they are read without the lock
+func (pl *ParkingLot) Tariff() *Tariff {
+	return pl.tariff
+}
+
+// Set the format registration numbers are validated and normalized by
+func (pl *ParkingLot) setPlateFormat(plates PlateFormat) {
+	pl.plates = plates
+}
+
+func (pl *ParkingLot) getPlateFormat() PlateFormat {
+	if pl.plates == nil {
+		return plateFormats[DefaultPlateFormat]
+	}
+	return pl.plates
+}
+
+// Get the log of overrides, oldest first
+func (pl *ParkingLot) Overrides() []*Override {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	c := newCopier()
+	var overrides []*Override
+	for _, override := range pl.overrides {
+		overrides = append(overrides, c.override(override))
+	}
+	return overrides
+}
+
+// Set the registry colours are matched by
+func (pl *ParkingLot) setColors(colors *ColorRegistry) {
+	pl.colors = colors
+}
+
+func (pl *ParkingLot) getColors() *ColorRegistry {
+	if pl.colors == nil {
+		return defaultColorRegistry
+	}
+	return pl.colors
+}
+
+// Set the operator on duty, recorded with every event from now on
+func (pl *ParkingLot) SetOperator(operator string) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.operator = operator
+}
+
+// Returns the operator on duty
+func (pl *ParkingLot) Operator() string {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return pl.operator
+}
+
+// Set the clock entry and exit times are taken from
+func (pl *ParkingLot) setClock(clock Clock) {
+	pl.clock = clock
+}
+
+func (pl *ParkingLot) Clock() Clock {
+	if pl.clock == nil {
+		return systemClock{}
+	}
+	return pl.clock
+}
+
+func (pl *ParkingLot) Floors() []*Floor {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	c := newCopier()
+	var floors []*Floor
+	for _, floor := range pl.floors {
+		floors = append(floors, c.floor(floor))
+	}
+	return floors
+}
+
+// Returns the number of floors, without copying them as Floors does
+func (pl *ParkingLot) FloorCount() int {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return len(pl.floors)
+}
+
+// Get every slot across floors, ordered by slot number
+func (pl *ParkingLot) Slots() []*Slot {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return newCopier().slotList(pl.slots)
+}
+
+// Returns the number of slots, or zero if the parking lot is not created
+func (pl *ParkingLot) Capacity() int {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	return pl.capacity
+}
+
+// Get the slots with a vehicle parked, ordered by slot number. A vehicle parked
+// in several slots is only included by the first of them. Only slots that have
+// been handed out at least once can be occupied.
+func (pl *ParkingLot) getOccupiedSlots() []*Slot {
+	if pl.allocator == nil {
+		return nil
+	}
+
+	var slots []*Slot
+
+	for _, slot := range pl.allocator.getUsedSlots() {
+		vehicle := slot.Vehicle()
+		if vehicle != nil && vehicle.Slots()[0] == slot {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].SlotNumber() < slots[j].SlotNumber()
+	})
+
+	return slots
+}
+
+func (pl *ParkingLot) isCreated() error {
+	if pl.capacity <= 0 {
+		return ErrNotCreated
+	}
+	return nil
+}
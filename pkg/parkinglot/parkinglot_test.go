@@ -0,0 +1,1032 @@
+package parkinglot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cedrickchee/go-parkinglot/internal/bitset"
+)
+
+func generateParkingSlot(capacity int) []*Slot {
+	var slots []*Slot
+
+	for i := 0; i < capacity; i++ {
+		slots = append(slots, &Slot{slotNumber: i + 1, floorNumber: 1, distance: i + 1, exitDistance: capacity - i, size: Medium})
+	}
+	return slots
+}
+
+// Generate a car with a registration number of its own
+func generateVehicle(i int) *Vehicle {
+	return NewVehicle(fmt.Sprintf("KA-01-HH-%04d", i), "White", Car, 0)
+}
+
+func generateFloors(slots []*Slot) []*Floor {
+	return []*Floor{{floorNumber: 1, slots: slots}}
+}
+
+// Generate the allocator of a lot with medium sized slots only, with the given
+// freed slot numbers free again. Slots in order from highestSlot on are free.
+func generateAllocator(slots []*Slot, emptySlot []int, highestSlot int) *slotAllocator {
+	allocator := &slotAllocator{free: bitset.New(len(slots)), slots: slots, policy: ExactSize, strategy: nearestEntry{}}
+	pool := newSlotPool(Medium, 0)
+	pool.order = slots
+	pool.highestSlot = highestSlot
+	pool.compact = bitset.New(len(slots))
+	pool.rank = allocator.strategy.Rank
+	for _, slotNumber := range emptySlot {
+		pool.compact.Add(slotNumber - 1)
+	}
+	allocator.pools = []*slotPool{pool}
+	for i, slot := range slots {
+		slot.position = i
+		if i >= highestSlot {
+			pool.compact.Add(i)
+		}
+		if slot.Vehicle() == nil {
+			allocator.free.Add(slot.SlotNumber() - 1)
+		}
+	}
+	return allocator
+}
+
+// Generate the index of the vehicles parked in the given slots
+func generateIndex(slots []*Slot) *vehicleIndex {
+	index := newVehicleIndex()
+	for _, slot := range slots {
+		if vehicle := slot.Vehicle(); vehicle != nil && len(vehicle.Slots()) > 0 && vehicle.Slots()[0] == slot {
+			index.add(vehicle)
+		}
+	}
+	return index
+}
+
+// The state of a slot pool compared by tests. The free slots are compared by
+// their slot numbers, whether the pool keeps them in a heap or a bitset.
+type poolState struct {
+	size        SlotSize
+	attributes  Attributes
+	free        []int
+	order       []*Slot
+	ranks       []int
+	highestSlot int
+}
+
+// Get the state of the pools of an allocator, or nil if there is no allocator
+func getPools(allocator *slotAllocator) []poolState {
+	if allocator == nil {
+		return nil
+	}
+	var pools []poolState
+	for _, pool := range allocator.pools {
+		pools = append(pools, poolState{
+			size:        pool.size,
+			attributes:  pool.attributes,
+			free:        getFreeSlotNumbers(pool),
+			order:       pool.order,
+			ranks:       pool.ranks,
+			highestSlot: pool.highestSlot,
+		})
+	}
+	return pools
+}
+
+// Get the slot numbers of the free slots in a pool, in ascending order
+func getFreeSlotNumbers(pool *slotPool) []int {
+	var free []int
+	if pool.compact != nil {
+		for i, slot := range pool.order {
+			if pool.compact.Contains(i) {
+				free = append(free, slot.SlotNumber())
+			}
+		}
+	} else {
+		for slotNumber := range pool.keys {
+			free = append(free, slotNumber)
+		}
+		for _, slot := range pool.order[pool.highestSlot:] {
+			free = append(free, slot.SlotNumber())
+		}
+	}
+	sort.Ints(free)
+	return free
+}
+
+type fields struct {
+	address    string
+	vehicle0   *Vehicle
+	vehicle1   *Vehicle
+	vehicle2   *Vehicle
+	slots      []*Slot
+	emptySlot0 []int
+	emptySlot1 []int
+}
+
+func genData() fields {
+	data := fields{
+		address:    "Marina Bay Sands",
+		vehicle0:   &Vehicle{registrationNumber: "KA-01-HH-2701", color: "Blue", vehicleType: Car},
+		vehicle1:   &Vehicle{registrationNumber: "KA-01-HH-1234", color: "White", vehicleType: Car},
+		vehicle2:   &Vehicle{registrationNumber: "KA-01-BB-0001", color: "Black", vehicleType: Car},
+		slots:      generateParkingSlot(10),
+		emptySlot1: []int{1},
+	}
+
+	return data
+}
+
+func compareParkingLot(t *testing.T, got *ParkingLot, want *ParkingLot) {
+	if !reflect.DeepEqual(getPools(got.allocator), getPools(want.allocator)) ||
+		!reflect.DeepEqual(got.floors, want.floors) ||
+		!reflect.DeepEqual(got.slots, want.slots) ||
+		got.address != want.address ||
+		got.capacity != want.capacity {
+		t.Errorf("ParkingLot got = %v, want = %v", got, want)
+	}
+}
+
+func TestCreateParkingLot(t *testing.T) {
+	data := genData()
+
+	type args struct {
+		address  string
+		capacity int
+	}
+	tests := []struct {
+		name       string
+		parkinglot *ParkingLot
+		args       args
+		want       *ParkingLot
+		wantErr    bool
+	}{
+		{
+			name:       "Parking lot is not created",
+			parkinglot: &ParkingLot{},
+			args: args{
+				address:  data.address,
+				capacity: 10,
+			},
+			want:    &ParkingLot{address: data.address, floors: generateFloors(data.slots), slots: data.slots, allocator: generateAllocator(data.slots, data.emptySlot0, 0), index: generateIndex(data.slots[:0]), capacity: 10},
+			wantErr: false,
+		},
+		{
+			name:       "Parking lot is already created",
+			parkinglot: &ParkingLot{address: data.address, floors: generateFloors(data.slots), slots: data.slots, allocator: generateAllocator(data.slots, data.emptySlot0, 0), index: generateIndex(data.slots[:0]), capacity: 10},
+			args: args{
+				address:  data.address,
+				capacity: 10,
+			},
+			want:    &ParkingLot{address: data.address, floors: generateFloors(data.slots), slots: data.slots, allocator: generateAllocator(data.slots, data.emptySlot0, 0), index: generateIndex(data.slots[:0]), capacity: 10},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parkinglot.createParkingLot(tt.args.address, tt.args.capacity)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("createParkingLot() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+
+			compareParkingLot(t, tt.parkinglot, tt.want)
+		})
+	}
+}
+
+func TestPark(t *testing.T) {
+	// Test data
+	data := genData()
+
+	slots := generateParkingSlot(2)
+	slots[0].vehicle = data.vehicle2
+	slotAfterParkedByVehicle2 := slots[0] // slots[0] is slot marked with number 1
+	// vehicle2 left slot 1, and then vehicle1 park at slot 1
+	slots[0].vehicle = data.vehicle1
+	slotAfterParkedByVehicle1 := slots[0]
+
+	type args struct {
+		registrationNumber string
+		color              string
+	}
+
+	tests := []struct {
+		name           string
+		parkinglot     *ParkingLot
+		args           args
+		wantSlot       *Slot
+		wantErr        bool
+		wantParkingLot *ParkingLot
+	}{
+		{
+			name:       "ParkingLot is not created",
+			parkinglot: &ParkingLot{},
+			args: args{
+				registrationNumber: data.vehicle1.registrationNumber,
+				color:              data.vehicle1.color,
+			},
+			wantSlot:       nil,
+			wantErr:        true,
+			wantParkingLot: &ParkingLot{},
+		},
+		{
+			name:       "Park vehicle into new slot",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 0), index: generateIndex(slots[:0]), capacity: 2},
+			args: args{
+				registrationNumber: data.vehicle2.registrationNumber,
+				color:              data.vehicle2.color,
+			},
+			wantSlot:       slotAfterParkedByVehicle2, // expected slotNumber = 1, vehicle2 with registrationNumber = KA-01-BB-0001
+			wantErr:        false,
+			wantParkingLot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 1), index: generateIndex(slots[:1]), capacity: 2},
+		},
+		{
+			name:       "Park vehicle into a previously occupied but now free slot",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot1, 1), index: generateIndex(slots[:1]), capacity: 2},
+			args: args{
+				registrationNumber: data.vehicle1.registrationNumber,
+				color:              data.vehicle1.color,
+			},
+			wantSlot:       slotAfterParkedByVehicle1, // expected slotNumber = 1, vehicle1 with registrationNumber = KA-01-HH-1234
+			wantErr:        false,
+			wantParkingLot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 1), index: generateIndex(slots[:1]), capacity: 2},
+		},
+		{
+			name:       "Park car when parking lot is full",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 2), index: generateIndex(slots[:2]), capacity: 2},
+			args: args{
+				registrationNumber: data.vehicle0.registrationNumber,
+				color:              data.vehicle0.color,
+			},
+			wantSlot:       nil,
+			wantErr:        true,
+			wantParkingLot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 2), index: generateIndex(slots[:2]), capacity: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket, err := tt.parkinglot.Park(NewVehicle(tt.args.registrationNumber, tt.args.color, Car, 0))
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Park() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var got *Slot
+			if ticket != nil {
+				got = ticket.Slots()[0]
+			}
+			if !reflect.DeepEqual(got, tt.wantSlot) {
+				t.Errorf("Park() got = %v, wantSlot %v", got, tt.wantSlot)
+			}
+
+			compareParkingLot(t, tt.parkinglot, tt.wantParkingLot)
+		})
+	}
+}
+
+func TestLeave(t *testing.T) {
+	data := genData()
+
+	slots := data.slots
+	slots[0].parkVehicle(data.vehicle0)
+	data.vehicle0.ticket = issueTicket(1, data.vehicle0, testTime)
+
+	type args struct {
+		slotNumber int
+	}
+
+	tests := []struct {
+		name           string
+		parkinglot     *ParkingLot
+		args           args
+		wantErr        bool
+		wantParkingLot *ParkingLot
+	}{
+		{
+			name:           "ParkingLot is not created",
+			parkinglot:     &ParkingLot{},
+			args:           args{slotNumber: 1},
+			wantErr:        true,
+			wantParkingLot: &ParkingLot{},
+		},
+		{
+			name:           "Leave existing vehicle",
+			parkinglot:     &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 2), index: generateIndex(slots[:2]), capacity: 10},
+			args:           args{slotNumber: 1},
+			wantErr:        false,
+			wantParkingLot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot1, 2), index: generateIndex(slots[:2]), capacity: 10},
+		},
+		{
+			name:           "Leave non-existent vehicle",
+			parkinglot:     &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot1, 2), index: generateIndex(slots[:2]), capacity: 10},
+			args:           args{slotNumber: 2},
+			wantErr:        true,
+			wantParkingLot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot1, 2), index: generateIndex(slots[:2]), capacity: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parkinglot.Leave(tt.args.slotNumber)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Leave() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			compareParkingLot(t, tt.parkinglot, tt.wantParkingLot)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	data := genData()
+
+	slots := data.slots
+	slots[0].parkVehicle(data.vehicle1)
+	slots[1].parkVehicle(data.vehicle2)
+
+	tests := []struct {
+		name       string
+		parkinglot *ParkingLot
+		want       []*Slot
+	}{
+		{
+			name:       "Parking lot is not created",
+			parkinglot: &ParkingLot{},
+			want:       nil,
+		},
+		{
+			name:       "Parking lot is empty",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 0), index: generateIndex(slots[:0]), capacity: 10},
+			want:       nil,
+		},
+		{
+			name:       "Parking lot with vehicles",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 2), index: generateIndex(slots[:2]), capacity: 10},
+			want: []*Slot{
+				{slotNumber: 1, floorNumber: 1, distance: 1, exitDistance: 10, size: Medium, vehicle: data.vehicle1, useCount: 1},
+				{slotNumber: 2, floorNumber: 1, distance: 2, exitDistance: 9, size: Medium, vehicle: data.vehicle2, useCount: 1, position: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parkinglot.Status(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Status() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVehiclesByColor(t *testing.T) {
+	data := genData()
+
+	slots := data.slots
+	slots[0].parkVehicle(data.vehicle1)
+
+	type args struct {
+		color string
+	}
+
+	tests := []struct {
+		name         string
+		parkinglot   *ParkingLot
+		args         args
+		wantSlot     []int
+		wantRegisNum []string
+		wantErr      bool
+		wantErrIs    error
+	}{
+		{
+			name:         "Parking lot is not created",
+			parkinglot:   &ParkingLot{},
+			args:         args{color: "White"},
+			wantSlot:     nil,
+			wantRegisNum: nil,
+			wantErr:      true,
+			wantErrIs:    ErrNotCreated,
+		},
+		{
+			name:         "A vehicle is parked and the color is White",
+			parkinglot:   &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 1), index: generateIndex(slots[:1]), capacity: 10},
+			args:         args{color: "White"},
+			wantSlot:     []int{1},
+			wantRegisNum: []string{"KA-01-HH-1234"},
+			wantErr:      false,
+		},
+		{
+			name:         "A vehicle is not parked with the requested color",
+			parkinglot:   &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot1, 2), index: generateIndex(slots[:2]), capacity: 10},
+			args:         args{color: "Black"},
+			wantSlot:     nil,
+			wantRegisNum: nil,
+			wantErr:      true,
+		},
+		{
+			name:         "Parking lot is empty",
+			parkinglot:   &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 0), index: generateIndex(slots[:0]), capacity: 10},
+			args:         args{color: "White"},
+			wantSlot:     nil,
+			wantRegisNum: nil,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSlots, gotRegisNumbers, err := tt.parkinglot.VehiclesByColor(tt.args.color)
+
+			if (err != nil) != tt.wantErr || (tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs)) {
+				t.Errorf("VehiclesByColor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotSlots, tt.wantSlot) {
+				t.Errorf("VehiclesByColor() gotSlots = %v, want %v", gotSlots, tt.wantSlot)
+			}
+			if !reflect.DeepEqual(gotRegisNumbers, tt.wantRegisNum) {
+				t.Errorf("VehiclesByColor() gotRegisNumbers = %v, want %v", gotRegisNumbers, tt.wantRegisNum)
+			}
+		})
+	}
+}
+
+func TestSlotNumberForRegistrationNumber(t *testing.T) {
+	data := genData()
+
+	slots := data.slots
+	slots[0].parkVehicle(data.vehicle1)
+
+	type args struct {
+		registrationNumber string
+	}
+
+	tests := []struct {
+		name       string
+		parkinglot *ParkingLot
+		args       args
+		want       int
+		wantErr    bool
+		wantErrIs  error
+	}{
+		{
+			name:       "Parking lot is not created",
+			parkinglot: &ParkingLot{},
+			args:       args{registrationNumber: "KA-01-HH-1234"},
+			want:       0,
+			wantErr:    true,
+			wantErrIs:  ErrNotCreated,
+		},
+		{
+			name:       "Parking lot has vehicle of given registration number",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 1), index: generateIndex(slots[:1]), capacity: 10},
+			args:       args{registrationNumber: "KA-01-HH-1234"},
+			want:       1,
+			wantErr:    false,
+		},
+		{
+			name:       "Parking lot don't have vehicle of given registration number",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot1, 2), index: generateIndex(slots[:2]), capacity: 10},
+			args:       args{registrationNumber: "KA-01-BB-0001"},
+			want:       0,
+			wantErr:    true,
+		},
+		{
+			name:       "Parking lot is empty",
+			parkinglot: &ParkingLot{address: data.address, slots: slots, allocator: generateAllocator(slots, data.emptySlot0, 0), index: generateIndex(slots[:0]), capacity: 10},
+			args:       args{registrationNumber: "KA-01-HH-1234"},
+			want:       0,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parkinglot.SlotNumberForRegistrationNumber(tt.args.registrationNumber)
+
+			if (err != nil) != tt.wantErr || (tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs)) {
+				t.Errorf("SlotNumberForRegistrationNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SlotNumberForRegistrationNumber() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateMultiStoreyParkingLot(t *testing.T) {
+	tests := []struct {
+		name         string
+		layouts      []FloorLayout
+		wantCapacity int
+		wantFloors   []int // Capacity of each floor
+		wantErr      bool
+	}{
+		{
+			name:         "Stacked floors",
+			layouts:      StackedFloors([]int{2, 3}),
+			wantCapacity: 5,
+			wantFloors:   []int{2, 3},
+			wantErr:      false,
+		},
+		{
+			name:    "No floors",
+			layouts: nil,
+			wantErr: true,
+		},
+		{
+			name:    "Floor without slots",
+			layouts: StackedFloors([]int{2, 0}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &ParkingLot{}
+			err := pl.createMultiStoreyParkingLot("Marina Bay Sands", tt.layouts, ExactSize, nil)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("createMultiStoreyParkingLot() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if pl.capacity != tt.wantCapacity {
+				t.Errorf("createMultiStoreyParkingLot() capacity = %v, want = %v", pl.capacity, tt.wantCapacity)
+			}
+			var gotFloors []int
+			for _, floor := range pl.Floors() {
+				gotFloors = append(gotFloors, floor.Capacity())
+			}
+			if !reflect.DeepEqual(gotFloors, tt.wantFloors) {
+				t.Errorf("createMultiStoreyParkingLot() floors = %v, want = %v", gotFloors, tt.wantFloors)
+			}
+			if got := pl.FloorCount(); got != len(tt.wantFloors) {
+				t.Errorf("FloorCount() got = %v, want = %v", got, len(tt.wantFloors))
+			}
+		})
+	}
+}
+
+func TestParkAcrossFloors(t *testing.T) {
+	tests := []struct {
+		name    string
+		layouts []FloorLayout
+		want    []int // Slot numbers in allocation order
+	}{
+		{
+			name:    "Stacked floors are filled bottom up",
+			layouts: StackedFloors([]int{2, 2}),
+			want:    []int{1, 2, 3, 4},
+		},
+		{
+			name:    "Entry point on the second floor",
+			layouts: []FloorLayout{{Capacity: 2, Distance: 10}, {Capacity: 2, Distance: 0}},
+			want:    []int{3, 4, 1, 2},
+		},
+		{
+			name:    "Floors at the same distance are interleaved",
+			layouts: []FloorLayout{{Capacity: 2, Distance: 0}, {Capacity: 2, Distance: 0}},
+			want:    []int{1, 3, 2, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &ParkingLot{}
+			if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", tt.layouts, ExactSize, nil); err != nil {
+				t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+			}
+
+			var got []int
+			for i := range tt.want {
+				ticket, err := pl.Park(generateVehicle(i))
+				if err != nil {
+					t.Fatalf("Park() error = %v", err)
+				}
+				got = append(got, ticket.Slots()[0].SlotNumber())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Park() got = %v, want = %v", got, tt.want)
+			}
+
+			// Free the farthest slot and the nearest one, the nearest is reused first
+			if _, err := pl.Leave(tt.want[len(tt.want)-1]); err != nil {
+				t.Fatalf("Leave() error = %v", err)
+			}
+			if _, err := pl.Leave(tt.want[0]); err != nil {
+				t.Fatalf("Leave() error = %v", err)
+			}
+			ticket, err := pl.Park(NewVehicle("KA-01-HH-9999", "White", Car, 0))
+			if err != nil {
+				t.Fatalf("Park() error = %v", err)
+			}
+			if got := ticket.Slots()[0].SlotNumber(); got != tt.want[0] {
+				t.Errorf("Park() got = %v, want = %v", got, tt.want[0])
+			}
+		})
+	}
+}
+
+func TestLeaveByTicket(t *testing.T) {
+	clock := NewFakeClock(testTime)
+	pl := &ParkingLot{}
+	pl.setClock(clock)
+	if err := pl.createParkingLot("Marina Bay Sands", 2); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+
+	first, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", Car, 0))
+	if err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+	second, err := pl.Park(NewVehicle("KA-01-HH-9999", "White", Car, 0))
+	if err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+	clock.Advance(90 * time.Minute)
+
+	tests := []struct {
+		name         string
+		ticketNumber int
+		wantSlot     int
+		wantErr      bool
+	}{
+		{
+			name:         "Leave with the second ticket",
+			ticketNumber: second.TicketNumber(),
+			wantSlot:     2,
+			wantErr:      false,
+		},
+		{
+			name:         "Leave with a closed ticket",
+			ticketNumber: second.TicketNumber(),
+			wantErr:      true,
+		},
+		{
+			name:         "Leave with an unknown ticket",
+			ticketNumber: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "Leave with the first ticket",
+			ticketNumber: first.TicketNumber(),
+			wantSlot:     1,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pl.LeaveByTicket(tt.ticketNumber)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LeaveByTicket() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if slot := got.Slots()[0].SlotNumber(); slot != tt.wantSlot {
+				t.Errorf("LeaveByTicket() slot = %v, want %v", slot, tt.wantSlot)
+			}
+			if got.Duration() != 90*time.Minute {
+				t.Errorf("LeaveByTicket() duration = %v, want %v", got.Duration(), 90*time.Minute)
+			}
+		})
+	}
+
+	// Closed tickets can still be queried
+	ticket, err := pl.Ticket(first.TicketNumber())
+	if err != nil {
+		t.Fatalf("Ticket() error = %v", err)
+	}
+	if !ticket.IsClosed() || ticket.Vehicle().RegistrationNumber() != "KA-01-HH-1234" {
+		t.Errorf("Ticket() got = %v", ticket)
+	}
+}
+
+func TestLeaveWithTariff(t *testing.T) {
+	tariff, err := LoadTariff("../../test/tariff.json")
+	if err != nil {
+		t.Fatalf("LoadTariff() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		tariff  *Tariff
+		stay    time.Duration
+		wantFee int64
+	}{
+		{
+			name:    "Parking lot without a tariff",
+			tariff:  nil,
+			stay:    3 * time.Hour,
+			wantFee: 0,
+		},
+		{
+			name:    "Parking lot with a tariff",
+			tariff:  tariff,
+			stay:    3 * time.Hour,
+			wantFee: 700,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(testTime)
+			pl := &ParkingLot{}
+			pl.setClock(clock)
+			pl.setTariff(tt.tariff)
+			if err := pl.createParkingLot("Marina Bay Sands", 1); err != nil {
+				t.Fatalf("createParkingLot() error = %v", err)
+			}
+
+			if _, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", Car, 0)); err != nil {
+				t.Fatalf("Park() error = %v", err)
+			}
+			clock.Advance(tt.stay)
+
+			ticket, err := pl.Leave(1)
+			if err != nil {
+				t.Fatalf("Leave() error = %v", err)
+			}
+			if ticket.Fee() != tt.wantFee {
+				t.Errorf("Leave() fee = %v, want %v", ticket.Fee(), tt.wantFee)
+			}
+		})
+	}
+}
+
+func TestOverridePark(t *testing.T) {
+	pl := &ParkingLot{}
+	if err := pl.createParkingLot("Marina Bay Sands", 3); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+	if _, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", Car, 0)); err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+
+	if _, err := pl.Park(NewVehicle("KA-01-HH-1234", "Black", Car, 0)); err == nil {
+		t.Errorf("Park() error = %v, wantErr = %v", err, true)
+	}
+	if _, err := pl.OverridePark(NewVehicle("KA-01-HH-1234", "Black", Car, 0), "", ""); err == nil {
+		t.Errorf("OverridePark() error = %v, wantErr = %v", err, true)
+	}
+	ticket, err := pl.OverridePark(NewVehicle("KA-01-HH-1234", "Black", Car, 0), "", "Cloned plate")
+	if err != nil {
+		t.Fatalf("OverridePark() error = %v", err)
+	}
+
+	overrides := pl.Overrides()
+	if len(overrides) != 1 || overrides[0].Ticket().TicketNumber() != ticket.TicketNumber() || overrides[0].Reason() != "Cloned plate" {
+		t.Errorf("Overrides() got = %v, want the override of ticket %v", overrides, ticket.TicketNumber())
+	}
+}
+
+// Park more vehicles than there are slots at the same moment through several
+// gates. Every slot must be handed out exactly once and the rest turned away.
+// Slots, vehicles, tickets, permits and events returned by the lot are copies,
+// which later changes to the lot and to the copies leave as they were
+func TestReturnedCopies(t *testing.T) {
+	pl := &ParkingLot{}
+	if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", StackedFloors([]int{2}), ExactSize, nil); err != nil {
+		t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+	}
+	vehicle := generateVehicle(1)
+	ticket, err := pl.Park(vehicle)
+	if err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+	if vehicle.Ticket() != nil || len(vehicle.Slots()) != 0 {
+		t.Errorf("Park() changed the vehicle passed in")
+	}
+	status := pl.Status()
+	if _, err := pl.Leave(1); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+
+	if ticket.IsClosed() {
+		t.Errorf("Leave() closed the ticket returned by Park()")
+	}
+	if len(status) != 1 || status[0].Vehicle() == nil || status[0].Vehicle().RegistrationNumber() != vehicle.RegistrationNumber() {
+		t.Errorf("Leave() emptied the slots returned by Status()")
+	}
+	if got := pl.Slot(1).Vehicle(); got != nil {
+		t.Errorf("Slot(1).Vehicle() got = %v, want = nil", got)
+	}
+
+	events, err := pl.HistoryForSlot(1)
+	if err != nil {
+		t.Fatalf("HistoryForSlot() error = %v", err)
+	}
+	events[0].SlotNumbers()[0] = 2
+	if event, err := pl.VehicleInSlotAt(1, ticket.EntryTime()); err != nil || event.SlotNumbers()[0] != 1 {
+		t.Errorf("VehicleInSlotAt() got = %v, error = %v, want the event of slot 1", event, err)
+	}
+	permit, err := pl.AddPermit(vehicle.RegistrationNumber(), ticket.EntryTime(), ticket.EntryTime(), 0)
+	if err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	permit.slotNumber = 2
+	if permits, err := pl.Permits(); err != nil || len(permits) != 1 || !permits[0].IsFloating() {
+		t.Errorf("Permits() got = %v, error = %v, want a floating permit", permits, err)
+	}
+}
+
+func TestConcurrentPark(t *testing.T) {
+	const capacity = 50
+	const vehicles = 200
+
+	pl := &ParkingLot{}
+	if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", StackedFloors([]int{20, 20, 10}), ExactSize, nil); err != nil {
+		t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+	}
+	gates := []string{"", "North", "South"}
+	for i, gate := range gates[1:] {
+		if err := pl.AddGate(gate, GateDistances(pl.Slots(), 1+i*(capacity-1))); err != nil {
+			t.Fatalf("AddGate() error = %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	slotNumbers := make(chan int, vehicles)
+	for i := 0; i < vehicles; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ticket, err := pl.ParkAtGate(generateVehicle(i), gates[i%len(gates)])
+			if errors.Is(err, ErrParkingLotFull) {
+				return
+			}
+			if err != nil {
+				t.Errorf("ParkAtGate() error = %v", err)
+				return
+			}
+			slotNumbers <- ticket.Slots()[0].SlotNumber()
+		}(i)
+	}
+	wg.Wait()
+	close(slotNumbers)
+
+	parked := make(map[int]bool)
+	for slotNumber := range slotNumbers {
+		if parked[slotNumber] {
+			t.Errorf("ParkAtGate() handed out slot %v twice", slotNumber)
+		}
+		parked[slotNumber] = true
+	}
+	if len(parked) != capacity {
+		t.Errorf("ParkAtGate() parked %v vehicles, want = %v", len(parked), capacity)
+	}
+	if got := len(pl.Status()); got != capacity {
+		t.Errorf("Status() got %v vehicles, want = %v", got, capacity)
+	}
+}
+
+// Park and leave from many goroutines while others read the lot. No slot may
+// be held by two vehicles at once, and once every vehicle has left every slot
+// must be free again.
+func TestConcurrentParkAndLeave(t *testing.T) {
+	const capacity = 40
+	const workers = 16
+	const rounds = 200
+
+	for _, name := range []string{"nearest_entry", "fill_from_back", "wear_levelling", "random"} {
+		t.Run(name, func(t *testing.T) {
+			newAllocator, err := LookupAllocator(name)
+			if err != nil {
+				t.Fatalf("LookupAllocator() error = %v", err)
+			}
+			clock := NewFakeClock(testTime)
+			pl := &ParkingLot{}
+			pl.setClock(clock)
+			if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", StackedFloors([]int{25, 15}), ExactSize, newAllocator); err != nil {
+				t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+			}
+
+			// Slots held by the workers, to catch a slot handed out twice. A
+			// slot is marked as leaving before its vehicle leaves, as another
+			// worker may be handed it before the mark is cleared.
+			type holder struct {
+				worker  int
+				leaving bool
+			}
+			var mu sync.Mutex
+			held := make(map[int]holder)
+
+			var workersDone sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				workersDone.Add(1)
+				go func(w int) {
+					defer workersDone.Done()
+					for r := 0; r < rounds; r++ {
+						vehicle := generateVehicle(w*rounds + r)
+						ticket, err := pl.Park(vehicle)
+						if errors.Is(err, ErrParkingLotFull) {
+							continue
+						}
+						if err != nil {
+							t.Errorf("Park() error = %v", err)
+							return
+						}
+						slotNumber := ticket.Slots()[0].SlotNumber()
+
+						mu.Lock()
+						if other, ok := held[slotNumber]; ok && !other.leaving {
+							t.Errorf("Park() handed out slot %v to worker %v, already held by worker %v", slotNumber, w, other.worker)
+						}
+						held[slotNumber] = holder{worker: w}
+						mu.Unlock()
+
+						// While the slot is held nobody else may be parked in it
+						if got := pl.Slot(slotNumber).Vehicle(); got == nil || got.RegistrationNumber() != vehicle.RegistrationNumber() {
+							t.Errorf("Slot(%v).Vehicle() got = %v, want = %v", slotNumber, got, vehicle.RegistrationNumber())
+						}
+
+						mu.Lock()
+						held[slotNumber] = holder{worker: w, leaving: true}
+						mu.Unlock()
+						if r%2 == 0 {
+							_, err = pl.Leave(slotNumber)
+						} else {
+							_, err = pl.LeaveByTicket(ticket.TicketNumber())
+						}
+						if err != nil {
+							t.Errorf("Leave() error = %v", err)
+							return
+						}
+						mu.Lock()
+						if held[slotNumber].worker == w {
+							delete(held, slotNumber)
+						}
+						mu.Unlock()
+					}
+				}(w)
+			}
+
+			var readersDone sync.WaitGroup
+			for r := 0; r < 4; r++ {
+				readersDone.Add(1)
+				go func(r int) {
+					defer readersDone.Done()
+					for i := 0; i < rounds; i++ {
+						if free, err := pl.FreeSlotCount(0); err != nil || free < 0 || free > capacity {
+							t.Errorf("FreeSlotCount() got = %v, %v", free, err)
+						}
+						slots := pl.Status()
+						if len(slots) > capacity {
+							t.Errorf("Status() got %v vehicles, more than %v slots", len(slots), capacity)
+						}
+						for _, slot := range slots {
+							vehicle := slot.Vehicle()
+							if vehicle == nil || vehicle.Slots()[0].SlotNumber() != slot.SlotNumber() || vehicle.Ticket().IsClosed() {
+								t.Errorf("Status() got slot %v without the vehicle parked in it", slot.SlotNumber())
+							}
+						}
+						pl.SlotNumberForRegistrationNumber(generateVehicle(r).RegistrationNumber())
+						pl.VehiclesByColor("White")
+						clock.Advance(time.Second)
+					}
+				}(r)
+			}
+
+			workersDone.Wait()
+			readersDone.Wait()
+
+			if free, _ := pl.FreeSlotCount(0); free != capacity {
+				t.Errorf("FreeSlotCount() after every vehicle left got = %v, want = %v", free, capacity)
+			}
+			if got := pl.Status(); len(got) != 0 {
+				t.Errorf("Status() after every vehicle left got %v vehicles, want none", len(got))
+			}
+
+			// Every slot can still be handed out, once
+			parked := make(map[int]bool)
+			for i := 0; i < capacity; i++ {
+				ticket, err := pl.Park(generateVehicle(workers*rounds + i))
+				if err != nil {
+					t.Fatalf("Park() error = %v", err)
+				}
+				parked[ticket.Slots()[0].SlotNumber()] = true
+			}
+			if len(parked) != capacity {
+				t.Errorf("Park() handed out %v distinct slots, want = %v", len(parked), capacity)
+			}
+			if _, err := pl.Park(generateVehicle(workers*rounds + capacity)); !errors.Is(err, ErrParkingLotFull) {
+				t.Errorf("Park() in a full lot error = %v, want = %v", err, ErrParkingLotFull)
+			}
+		})
+	}
+}
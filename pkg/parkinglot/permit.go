@@ -0,0 +1,223 @@
+package parkinglot
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// A Permit lets a vehicle park for a period of days, either in a dedicated
+// slot or in a share of the floating pool of reserved slots
+type Permit struct {
+	registrationNumber string
+	validFrom          time.Time // First day of the permit
+	validUntil         time.Time // Last day of the permit
+	slotNumber         int       // Dedicated slot, zero for a permit in the floating pool
+}
+
+// Create a permit valid from the start of the first day to the end of the last day
+func createPermit(registrationNumber string, validFrom, validUntil time.Time, slotNumber int) *Permit {
+	return &Permit{registrationNumber: registrationNumber, validFrom: validFrom, validUntil: validUntil, slotNumber: slotNumber}
+}
+
+func (p *Permit) RegistrationNumber() string {
+	return p.registrationNumber
+}
+
+func (p *Permit) ValidFrom() time.Time {
+	return p.validFrom
+}
+
+func (p *Permit) ValidUntil() time.Time {
+	return p.validUntil
+}
+
+// Returns the dedicated slot number, zero for a permit in the floating pool
+func (p *Permit) SlotNumber() int {
+	return p.slotNumber
+}
+
+// Reports whether the permit is in the floating pool
+func (p *Permit) IsFloating() bool {
+	return p.slotNumber == 0
+}
+
+// Reports whether the permit is valid at a point in time
+func (p *Permit) IsValidAt(t time.Time) bool {
+	return !t.Before(p.validFrom) && t.Before(p.validUntil.AddDate(0, 0, 1))
+}
+
+// Reports whether the permit has expired at a point in time
+func (p *Permit) IsExpiredAt(t time.Time) bool {
+	return !t.Before(p.validUntil.AddDate(0, 0, 1))
+}
+
+// Returns the number of whole days from a point in time to the last day of
+// the permit, negative once it has expired
+func (p *Permit) DaysLeft(t time.Time) int {
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, p.validUntil.Location())
+	return int(math.Round(p.validUntil.Sub(today).Hours() / 24))
+}
+
+// A permitRegistry holds the permit of each registration number, and the
+// slots reserved for them. Dedicated slots are held out of allocation from
+// the time their permit becomes valid until it is revoked or expires,
+// floating permits share a pool of reserved slots that other vehicles can not
+// take the last of.
+type permitRegistry struct {
+	permits   map[string]*Permit
+	dedicated map[int]*Permit // Permit of each dedicated slot number
+	held      map[int]bool    // Dedicated slot numbers held out of allocation
+	pool      int             // Slots reserved for floating permits
+}
+
+func newPermitRegistry() *permitRegistry {
+	return &permitRegistry{permits: make(map[string]*Permit), dedicated: make(map[int]*Permit), held: make(map[int]bool)}
+}
+
+// Add a permit, replacing any permit of its registration number
+func (r *permitRegistry) add(permit *Permit) {
+	r.permits[permit.registrationNumber] = permit
+	if !permit.IsFloating() {
+		r.dedicated[permit.slotNumber] = permit
+	}
+}
+
+// Remove the permit of a registration number, if it has one
+func (r *permitRegistry) remove(registrationNumber string) {
+	permit, ok := r.permits[registrationNumber]
+	if !ok {
+		return
+	}
+	delete(r.permits, registrationNumber)
+	if r.dedicated[permit.slotNumber] == permit {
+		delete(r.dedicated, permit.slotNumber)
+		delete(r.held, permit.slotNumber)
+	}
+}
+
+// Get the permit of a registration number, valid or not
+func (r *permitRegistry) get(registrationNumber string) (*Permit, bool) {
+	permit, ok := r.permits[registrationNumber]
+	return permit, ok
+}
+
+// Get the permit of a registration number valid at a point in time, or nil
+func (r *permitRegistry) getValid(registrationNumber string, t time.Time) *Permit {
+	if r == nil {
+		return nil
+	}
+	if permit, ok := r.permits[registrationNumber]; ok && permit.IsValidAt(t) {
+		return permit
+	}
+	return nil
+}
+
+// Get the permit a slot is dedicated to, or nil
+func (r *permitRegistry) getDedicated(slotNumber int) *Permit {
+	if r == nil {
+		return nil
+	}
+	return r.dedicated[slotNumber]
+}
+
+// Reports whether a dedicated slot is held out of allocation
+func (r *permitRegistry) isHeld(slotNumber int) bool {
+	return r != nil && r.held[slotNumber]
+}
+
+// Hold a dedicated slot out of allocation
+func (r *permitRegistry) hold(slotNumber int) {
+	r.held[slotNumber] = true
+}
+
+// Get the dedicated slots of permits valid at a point in time that are not
+// held yet, in ascending order
+func (r *permitRegistry) getUnheld(t time.Time) []int {
+	if r == nil {
+		return nil
+	}
+	var slotNumbers []int
+	for slotNumber, permit := range r.dedicated {
+		if !r.held[slotNumber] && permit.IsValidAt(t) {
+			slotNumbers = append(slotNumbers, slotNumber)
+		}
+	}
+	sort.Ints(slotNumbers)
+	return slotNumbers
+}
+
+// Stop dedicating slots to permits expired at a point in time. Returns the
+// numbers of the slots that were held, in ascending order.
+func (r *permitRegistry) expire(t time.Time) []int {
+	if r == nil {
+		return nil
+	}
+	var slotNumbers []int
+	for slotNumber, permit := range r.dedicated {
+		if permit.IsExpiredAt(t) {
+			delete(r.dedicated, slotNumber)
+			if r.held[slotNumber] {
+				delete(r.held, slotNumber)
+				slotNumbers = append(slotNumbers, slotNumber)
+			}
+		}
+	}
+	sort.Ints(slotNumbers)
+	return slotNumbers
+}
+
+// Get every permit, ordered by registration number
+func (r *permitRegistry) getPermits() []*Permit {
+	var permits []*Permit
+	for _, permit := range r.permits {
+		permits = append(permits, permit)
+	}
+	sort.Slice(permits, func(i, j int) bool {
+		return permits[i].registrationNumber < permits[j].registrationNumber
+	})
+	return permits
+}
+
+// Get the permits whose last day is at most the given number of days after a
+// point in time, including those already expired, soonest to expire first
+func (r *permitRegistry) getExpiring(t time.Time, days int) []*Permit {
+	var permits []*Permit
+	for _, permit := range r.getPermits() {
+		if permit.DaysLeft(t) <= days {
+			permits = append(permits, permit)
+		}
+	}
+	sort.SliceStable(permits, func(i, j int) bool {
+		return permits[i].validUntil.Before(permits[j].validUntil)
+	})
+	return permits
+}
+
+// Returns the number of floating pool slots still owed at a point in time, to
+// valid floating permits whose vehicles are not parked. Reports whether a
+// registration number is parked with isParked.
+func (r *permitRegistry) getReservedCount(t time.Time, isParked func(string) bool) int {
+	if r == nil {
+		return 0
+	}
+	owed, parked := 0, 0
+	for _, permit := range r.permits {
+		if !permit.IsFloating() || !permit.IsValidAt(t) {
+			continue
+		}
+		if isParked(permit.registrationNumber) {
+			parked++
+		} else {
+			owed++
+		}
+	}
+	reserved := r.pool - parked
+	if owed < reserved {
+		reserved = owed
+	}
+	if reserved < 0 {
+		return 0
+	}
+	return reserved
+}
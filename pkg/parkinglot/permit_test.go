@@ -0,0 +1,252 @@
+package parkinglot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPermitValidity(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+	}
+	permit := createPermit("KA-01-HH-1234", day(10), day(20), 0)
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		valid    bool
+		expired  bool
+		daysLeft int
+	}{
+		{"before the first day", day(9).Add(23 * time.Hour), false, false, 11},
+		{"start of the first day", day(10), true, false, 10},
+		{"during the last day", day(20).Add(23 * time.Hour), true, false, 0},
+		{"after the last day", day(21), false, true, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permit.IsValidAt(tt.t); got != tt.valid {
+				t.Errorf("IsValidAt() got = %v, want = %v", got, tt.valid)
+			}
+			if got := permit.IsExpiredAt(tt.t); got != tt.expired {
+				t.Errorf("IsExpiredAt() got = %v, want = %v", got, tt.expired)
+			}
+			if got := permit.DaysLeft(tt.t); got != tt.daysLeft {
+				t.Errorf("DaysLeft() got = %v, want = %v", got, tt.daysLeft)
+			}
+		})
+	}
+}
+
+// Create a parking lot of the given capacity with a fake clock at testTime
+func createParkingLotWithClock(t *testing.T, capacity int) (*ParkingLot, *FakeClock) {
+	t.Helper()
+	pl := &ParkingLot{}
+	if err := pl.createParkingLot("Marina Bay Sands", capacity); err != nil {
+		t.Fatalf("createParkingLot() error = %v", err)
+	}
+	clock := NewFakeClock(testTime)
+	pl.setClock(clock)
+	return pl, clock
+}
+
+func TestDedicatedPermit(t *testing.T) {
+	pl, clock := createParkingLotWithClock(t, 2)
+	validFrom := testTime.AddDate(0, 0, -1)
+	if _, err := pl.AddPermit("KA-01-HH-1234", validFrom, testTime, 1); err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	if _, err := pl.AddPermit("KA-01-HH-9999", validFrom, testTime, 1); err == nil {
+		t.Errorf("AddPermit() error = %v, wantErr = %v", err, true)
+	}
+
+	// General traffic can not take the dedicated slot
+	if ticket, err := pl.Park(generateVehicle(1)); err != nil || ticket.Slots()[0].SlotNumber() != 2 {
+		t.Fatalf("Park() got = %v, error = %v, want slot 2", ticket, err)
+	}
+	if _, err := pl.Park(generateVehicle(2)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+
+	// The holder parks in the dedicated slot, which stays held after leaving
+	ticket, err := pl.Park(generateVehicle(1234))
+	if err != nil || ticket.Slots()[0].SlotNumber() != 1 {
+		t.Fatalf("Park() got = %v, error = %v, want slot 1", ticket, err)
+	}
+	if !ticket.Vehicle().IsPermitHolder() {
+		t.Errorf("IsPermitHolder() got = false, want = true")
+	}
+	if _, err := pl.Leave(1); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if count, _ := pl.FreeSlotCount(0); count != 0 {
+		t.Errorf("FreeSlotCount() got = %v, want = 0", count)
+	}
+
+	// The slot is released once the permit expires
+	clock.Advance(24 * time.Hour)
+	if ticket, err := pl.Park(generateVehicle(2)); err != nil || ticket.Slots()[0].SlotNumber() != 1 {
+		t.Errorf("Park() got = %v, error = %v, want slot 1", ticket, err)
+	}
+}
+
+// A permit valid from a later day leaves its dedicated slot to general traffic
+// until then, and holds it once the vehicle parked in it leaves
+func TestFutureDedicatedPermit(t *testing.T) {
+	pl, clock := createParkingLotWithClock(t, 2)
+	validFrom := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := pl.AddPermit("KA-01-HH-1234", validFrom, validFrom.AddDate(0, 0, 30), 1); err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	for _, want := range []int{1, 2} {
+		if ticket, err := pl.Park(generateVehicle(want)); err != nil || ticket.Slots()[0].SlotNumber() != want {
+			t.Fatalf("Park() got = %v, error = %v, want slot %v", ticket, err, want)
+		}
+	}
+
+	// The holder is not let in before the permit is valid
+	if _, err := pl.Park(generateVehicle(1234)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() before the permit is valid error = %v, want = %v", err, ErrParkingLotFull)
+	}
+
+	clock.Advance(validFrom.Sub(testTime))
+	if _, err := pl.Leave(1); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if _, err := pl.Park(generateVehicle(3)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+	if ticket, err := pl.Park(generateVehicle(1234)); err != nil || ticket.Slots()[0].SlotNumber() != 1 {
+		t.Errorf("Park() of the holder got = %v, error = %v, want slot 1", ticket, err)
+	}
+
+	// A slot free when its permit becomes valid is held straight away
+	if _, err := pl.AddPermit("KA-01-HH-9999", validFrom.AddDate(0, 0, 1), validFrom.AddDate(0, 0, 30), 2); err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	if _, err := pl.Leave(2); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	clock.Advance(24 * time.Hour)
+	if _, err := pl.Park(generateVehicle(4)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() once the permit is valid error = %v, want = %v", err, ErrParkingLotFull)
+	}
+}
+
+func TestRevokePermit(t *testing.T) {
+	pl, _ := createParkingLotWithClock(t, 1)
+	if _, err := pl.AddPermit("KA-01-HH-1234", testTime, testTime, 1); err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	if _, err := pl.Park(generateVehicle(1)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+	if err := pl.RevokePermit("KA-01-HH-1234"); err != nil {
+		t.Fatalf("RevokePermit() error = %v", err)
+	}
+	if err := pl.RevokePermit("KA-01-HH-1234"); err == nil {
+		t.Errorf("RevokePermit() error = %v, wantErr = %v", err, true)
+	}
+	if _, err := pl.Park(generateVehicle(1)); err != nil {
+		t.Errorf("Park() error = %v", err)
+	}
+}
+
+func TestFloatingPermitPool(t *testing.T) {
+	pl, _ := createParkingLotWithClock(t, 4)
+	for _, n := range []int{1, 2, 3} {
+		if _, err := pl.AddPermit(generateVehicle(n).RegistrationNumber(), testTime, testTime, 0); err != nil {
+			t.Fatalf("AddPermit() error = %v", err)
+		}
+	}
+	if err := pl.SetPermitPool(5); err == nil {
+		t.Errorf("SetPermitPool() error = %v, wantErr = %v", err, true)
+	}
+	if err := pl.SetPermitPool(2); err != nil {
+		t.Fatalf("SetPermitPool() error = %v", err)
+	}
+
+	// Three permits share two reserved slots, leaving two for general traffic
+	for _, n := range []int{10, 11} {
+		if _, err := pl.Park(generateVehicle(n)); err != nil {
+			t.Fatalf("Park() error = %v", err)
+		}
+	}
+	if _, err := pl.Park(generateVehicle(12)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+	for _, n := range []int{1, 2} {
+		if _, err := pl.Park(generateVehicle(n)); err != nil {
+			t.Errorf("Park() error = %v", err)
+		}
+	}
+	if _, err := pl.Park(generateVehicle(3)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+
+	// Once a permit holder leaves, its slot is owed to the one still away
+	if _, err := pl.Leave(3); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if _, err := pl.Park(generateVehicle(12)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+	if _, err := pl.Park(generateVehicle(3)); err != nil {
+		t.Errorf("Park() error = %v", err)
+	}
+}
+
+// General traffic can not take the last slots of a size owed to floating
+// permit holders, however many slots of other sizes are free
+func TestFloatingPermitPoolMixedSizes(t *testing.T) {
+	pl := &ParkingLot{}
+	layouts := []FloorLayout{{Capacity: 5, Sizes: []SlotSize{Small, Small, Small}}}
+	if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", layouts, ExactSize, nil); err != nil {
+		t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+	}
+	pl.setClock(NewFakeClock(testTime))
+	for _, n := range []int{1, 2} {
+		if _, err := pl.AddPermit(generateVehicle(n).RegistrationNumber(), testTime, testTime, 0); err != nil {
+			t.Fatalf("AddPermit() error = %v", err)
+		}
+	}
+	if err := pl.SetPermitPool(2); err != nil {
+		t.Fatalf("SetPermitPool() error = %v", err)
+	}
+
+	if _, err := pl.Park(generateVehicle(10)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() of a car error = %v, want = %v", err, ErrParkingLotFull)
+	}
+	if _, err := pl.Park(NewVehicle("KA-01-HH-0011", "White", Motorcycle, 0)); err != nil {
+		t.Errorf("Park() of a motorcycle error = %v", err)
+	}
+	for _, want := range []int{4, 5} {
+		if ticket, err := pl.Park(generateVehicle(want - 3)); err != nil || ticket.Slots()[0].SlotNumber() != want {
+			t.Errorf("Park() of a permit holder got = %v, error = %v, want slot %v", ticket, err, want)
+		}
+	}
+}
+
+func TestExpiringPermits(t *testing.T) {
+	pl, _ := createParkingLotWithClock(t, 1)
+	for i, days := range []int{10, -2, 3} {
+		validUntil := testTime.AddDate(0, 0, days)
+		if _, err := pl.AddPermit(generateVehicle(i).RegistrationNumber(), validUntil.AddDate(0, -1, 0), validUntil, 0); err != nil {
+			t.Fatalf("AddPermit() error = %v", err)
+		}
+	}
+
+	permits, err := pl.ExpiringPermits(7)
+	if err != nil {
+		t.Fatalf("ExpiringPermits() error = %v", err)
+	}
+	var got []string
+	for _, permit := range permits {
+		got = append(got, permit.RegistrationNumber())
+	}
+	if want := []string{"KA-01-HH-0001", "KA-01-HH-0002"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpiringPermits() got = %v, want = %v", got, want)
+	}
+}
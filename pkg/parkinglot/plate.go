@@ -0,0 +1,93 @@
+package parkinglot
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A PlateFormat validates registration numbers of one country and converts
+// them to a canonical form, so that the same plate typed differently is
+// recognised as one vehicle
+type PlateFormat interface {
+	// Returns the canonical form of a registration number, or an error if it
+	// is not valid in this format
+	Normalize(registrationNumber string) (string, error)
+}
+
+// Built-in plate formats by name
+var plateFormats = map[string]PlateFormat{
+	"generic":     genericPlate{},
+	"indian":      indianPlate{},
+	"singaporean": singaporeanPlate{},
+}
+
+// The plate format used unless another one is given
+const DefaultPlateFormat = "generic"
+
+// Look up a built-in plate format by name, such as "indian"
+func LookupPlateFormat(name string) (PlateFormat, error) {
+	format, ok := plateFormats[strings.ToLower(name)]
+	if !ok {
+		var names []string
+		for name := range plateFormats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Unknown plate format: %v, use one of %v", name, strings.Join(names, ", "))
+	}
+	return format, nil
+}
+
+var (
+	genericPlatePattern     = regexp.MustCompile(`^[A-Z0-9]+([- ]+[A-Z0-9]+)*$`)
+	genericPlateSeparator   = regexp.MustCompile(`[- ]+`)
+	genericPlateGroup       = regexp.MustCompile(`[A-Z]+|[0-9]+`)
+	indianPlatePattern      = regexp.MustCompile(`^([A-Z]{2})[- ]*([0-9]{1,2})[- ]*([A-Z]{0,3})[- ]*([0-9]{1,4})$`)
+	singaporeanPlatePattern = regexp.MustCompile(`^([A-Z]{1,3})[- ]*([0-9]{1,4})[- ]*([A-Z])$`)
+)
+
+// Accepts letters and digits, optionally separated by spaces or hyphens. The
+// separators typed are dropped and the plate is split into groups of letters
+// and of digits instead, so "AB 123 CD" and "ab123cd" both become "AB-123-CD"
+type genericPlate struct{}
+
+func (genericPlate) Normalize(registrationNumber string) (string, error) {
+	s := strings.ToUpper(strings.TrimSpace(registrationNumber))
+	if !genericPlatePattern.MatchString(s) {
+		return "", fmt.Errorf("Invalid registration number: %v", registrationNumber)
+	}
+	s = genericPlateSeparator.ReplaceAllString(s, "")
+	return strings.Join(genericPlateGroup.FindAllString(s, -1), "-"), nil
+}
+
+// Accepts a state code, district number, optional series and number, such as
+// "ka 1 hh 1234", which becomes "KA-01-HH-1234". The district is padded to two
+// digits and the number to four, so "KA-1-HH-1" is "KA-01-HH-0001".
+type indianPlate struct{}
+
+func (indianPlate) Normalize(registrationNumber string) (string, error) {
+	m := indianPlatePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(registrationNumber)))
+	if m == nil {
+		return "", fmt.Errorf("Invalid Indian registration number: %v", registrationNumber)
+	}
+	groups := []string{m[1], fmt.Sprintf("%02s", m[2])}
+	if m[3] != "" {
+		groups = append(groups, m[3])
+	}
+	groups = append(groups, fmt.Sprintf("%04s", m[4]))
+	return strings.Join(groups, "-"), nil
+}
+
+// Accepts a prefix, number and suffix letter, such as "sba 1234 a", which
+// becomes "SBA1234A"
+type singaporeanPlate struct{}
+
+func (singaporeanPlate) Normalize(registrationNumber string) (string, error) {
+	m := singaporeanPlatePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(registrationNumber)))
+	if m == nil {
+		return "", fmt.Errorf("Invalid Singaporean registration number: %v", registrationNumber)
+	}
+	return m[1] + m[2] + m[3], nil
+}
@@ -0,0 +1,105 @@
+package parkinglot
+
+import (
+	"testing"
+)
+
+func TestPlateFormatNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "Generic plate keeps its groups",
+			format: "generic",
+			input:  "ka-01-hh-1234",
+			want:   "KA-01-HH-1234",
+		},
+		{
+			name:   "Generic plate separated by spaces",
+			format: "generic",
+			input:  " AB  123 cd ",
+			want:   "AB-123-CD",
+		},
+		{
+			name:   "Generic plate without separators",
+			format: "generic",
+			input:  "KA01HH1234",
+			want:   "KA-01-HH-1234",
+		},
+		{
+			name:   "Generic plate with separators inside a group",
+			format: "generic",
+			input:  "KA01-HH12 34",
+			want:   "KA-01-HH-1234",
+		},
+		{
+			name:    "Generic plate with other characters",
+			format:  "generic",
+			input:   "AB_123",
+			wantErr: true,
+		},
+		{
+			name:   "Indian plate without separators",
+			format: "indian",
+			input:  "KA01HH1234",
+			want:   "KA-01-HH-1234",
+		},
+		{
+			name:   "Indian plate with spaces and a single digit district",
+			format: "indian",
+			input:  "ka 1 hh 1234",
+			want:   "KA-01-HH-1234",
+		},
+		{
+			name:   "Indian plate without series",
+			format: "indian",
+			input:  "DL-3-333",
+			want:   "DL-03-0333",
+		},
+		{
+			name:   "Indian plate with a short number",
+			format: "indian",
+			input:  "KA-1-HH-1",
+			want:   "KA-01-HH-0001",
+		},
+		{
+			name:    "Indian plate with a number too long",
+			format:  "indian",
+			input:   "KA-01-HH-12345",
+			wantErr: true,
+		},
+		{
+			name:   "Singaporean plate",
+			format: "singaporean",
+			input:  "sba 1234 a",
+			want:   "SBA1234A",
+		},
+		{
+			name:    "Singaporean plate without suffix",
+			format:  "singaporean",
+			input:   "SBA1234",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := LookupPlateFormat(tt.format)
+			if err != nil {
+				t.Fatalf("LookupPlateFormat() error = %v", err)
+			}
+			got, err := format.Normalize(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Normalize() error = %v, wantErr = %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
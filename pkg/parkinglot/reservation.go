@@ -0,0 +1,201 @@
+package parkinglot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Time after the start of its window a reservation holds its slot, unless
+// configured otherwise
+const DefaultNoShowAfter = 15 * time.Minute
+
+// A ReservationStatus is the stage a reservation has reached
+type ReservationStatus int
+
+const (
+	Pending   ReservationStatus = iota // The window has not started yet
+	Held                               // The slot is held for the vehicle to arrive
+	Fulfilled                          // The vehicle arrived
+	NoShow                             // The vehicle did not arrive in time and the slot was released
+	Cancelled                          // The reservation was cancelled
+)
+
+var reservationStatusNames = []string{"pending", "held", "fulfilled", "no-show", "cancelled"}
+
+func (s ReservationStatus) String() string {
+	return reservationStatusNames[s]
+}
+
+// Parse a reservation status name, such as "no-show"
+func parseReservationStatus(name string) (ReservationStatus, error) {
+	for i, statusName := range reservationStatusNames {
+		if name == statusName {
+			return ReservationStatus(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown reservation status: %v", name)
+}
+
+// Reports whether the reservation still claims its slot
+func (s ReservationStatus) isActive() bool {
+	return s == Pending || s == Held
+}
+
+// A Reservation claims a slot for a vehicle over a window of time. The slot is
+// held from the start of the window until the vehicle arrives, or until the
+// no-show time has passed.
+type Reservation struct {
+	reservationNumber  int
+	registrationNumber string
+	slotNumber         int
+	start              time.Time
+	end                time.Time
+	status             ReservationStatus
+}
+
+func (r *Reservation) ReservationNumber() int {
+	return r.reservationNumber
+}
+
+func (r *Reservation) RegistrationNumber() string {
+	return r.registrationNumber
+}
+
+func (r *Reservation) SlotNumber() int {
+	return r.slotNumber
+}
+
+func (r *Reservation) Start() time.Time {
+	return r.start
+}
+
+func (r *Reservation) End() time.Time {
+	return r.end
+}
+
+func (r *Reservation) Status() ReservationStatus {
+	return r.status
+}
+
+// Reports whether the window of the reservation overlaps another window
+func (r *Reservation) overlaps(start, end time.Time) bool {
+	return r.start.Before(end) && start.Before(r.end)
+}
+
+// A reservationBook holds every reservation made, and the active reservations
+// of each slot
+type reservationBook struct {
+	reservations []*Reservation         // Ordered by reservation number
+	bySlot       map[int][]*Reservation // Active reservations of each slot number
+}
+
+func newReservationBook() *reservationBook {
+	return &reservationBook{bySlot: make(map[int][]*Reservation)}
+}
+
+// Add a reservation and give it the next reservation number
+func (b *reservationBook) add(reservation *Reservation) {
+	reservation.reservationNumber = len(b.reservations) + 1
+	b.reservations = append(b.reservations, reservation)
+	b.bySlot[reservation.slotNumber] = append(b.bySlot[reservation.slotNumber], reservation)
+}
+
+// Get a reservation by its number, or nil
+func (b *reservationBook) get(reservationNumber int) *Reservation {
+	if b == nil || reservationNumber <= 0 || reservationNumber > len(b.reservations) {
+		return nil
+	}
+	return b.reservations[reservationNumber-1]
+}
+
+// Set the status of a reservation. A reservation no longer active stops
+// claiming its slot.
+func (b *reservationBook) setStatus(reservation *Reservation, status ReservationStatus) {
+	reservation.status = status
+	if status.isActive() {
+		return
+	}
+	active := b.bySlot[reservation.slotNumber]
+	for i, r := range active {
+		if r == reservation {
+			active = append(active[:i:i], active[i+1:]...)
+			break
+		}
+	}
+	if len(active) == 0 {
+		delete(b.bySlot, reservation.slotNumber)
+	} else {
+		b.bySlot[reservation.slotNumber] = active
+	}
+}
+
+// Get the active reservation of a slot whose window overlaps the given window,
+// or nil
+func (b *reservationBook) getConflict(slotNumber int, start, end time.Time) *Reservation {
+	if b == nil {
+		return nil
+	}
+	for _, reservation := range b.bySlot[slotNumber] {
+		if reservation.overlaps(start, end) {
+			return reservation
+		}
+	}
+	return nil
+}
+
+// Reports whether a slot has any active reservation
+func (b *reservationBook) isReserved(slotNumber int) bool {
+	return b != nil && len(b.bySlot[slotNumber]) > 0
+}
+
+// Get the earliest reservation of a registration number whose slot is held,
+// or nil
+func (b *reservationBook) getHeldFor(registrationNumber string) *Reservation {
+	var held *Reservation
+	if b == nil {
+		return nil
+	}
+	for _, active := range b.bySlot {
+		for _, reservation := range active {
+			if reservation.status == Held && reservation.registrationNumber == registrationNumber &&
+				(held == nil || reservation.reservationNumber < held.reservationNumber) {
+				held = reservation
+			}
+		}
+	}
+	return held
+}
+
+// Get the active reservation of a registration number whose window overlaps
+// the given window, or nil
+func (b *reservationBook) getOverlapping(registrationNumber string, start, end time.Time) *Reservation {
+	for _, active := range b.bySlot {
+		for _, reservation := range active {
+			if reservation.registrationNumber == registrationNumber && reservation.overlaps(start, end) {
+				return reservation
+			}
+		}
+	}
+	return nil
+}
+
+// Get the active reservations whose window has started at a point in time,
+// ordered by reservation number
+func (b *reservationBook) getStarted(t time.Time) []*Reservation {
+	if b == nil {
+		return nil
+	}
+	var started []*Reservation
+	for _, active := range b.bySlot {
+		for _, reservation := range active {
+			if !t.Before(reservation.start) {
+				started = append(started, reservation)
+			}
+		}
+	}
+	sort.Slice(started, func(i, j int) bool {
+		return started[i].reservationNumber < started[j].reservationNumber
+	})
+	return started
+}
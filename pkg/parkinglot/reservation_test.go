@@ -0,0 +1,123 @@
+package parkinglot
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReservationBook(t *testing.T) {
+	b := newReservationBook()
+	first := &Reservation{registrationNumber: "KA-01-HH-0001", slotNumber: 1, start: testTime, end: testTime.Add(time.Hour)}
+	b.add(first)
+	if first.ReservationNumber() != 1 || b.get(1) != first || b.get(2) != nil {
+		t.Fatalf("add() numbered the reservation %v", first.ReservationNumber())
+	}
+
+	tests := []struct {
+		name       string
+		slotNumber int
+		start      time.Time
+		end        time.Time
+		conflict   bool
+	}{
+		{"same window", 1, testTime, testTime.Add(time.Hour), true},
+		{"overlaps the end", 1, testTime.Add(30 * time.Minute), testTime.Add(2 * time.Hour), true},
+		{"ends at the start", 1, testTime.Add(-time.Hour), testTime, false},
+		{"starts at the end", 1, testTime.Add(time.Hour), testTime.Add(2 * time.Hour), false},
+		{"another slot", 2, testTime, testTime.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.getConflict(tt.slotNumber, tt.start, tt.end) != nil; got != tt.conflict {
+				t.Errorf("getConflict() got = %v, want = %v", got, tt.conflict)
+			}
+		})
+	}
+
+	if got := b.getStarted(testTime.Add(-time.Minute)); len(got) != 0 {
+		t.Errorf("getStarted() got = %v, want none", got)
+	}
+	if got := b.getStarted(testTime); len(got) != 1 {
+		t.Errorf("getStarted() got = %v, want the reservation", got)
+	}
+
+	b.setStatus(first, Cancelled)
+	if b.isReserved(1) || b.getConflict(1, testTime, testTime.Add(time.Hour)) != nil {
+		t.Errorf("setStatus() left the cancelled reservation claiming its slot")
+	}
+}
+
+func TestReserve(t *testing.T) {
+	pl, clock := createParkingLotWithClock(t, 2)
+	reservation, err := pl.Reserve("KA-01-HH-1234", Car, testTime.Add(time.Hour), time.Hour, 0)
+	if err != nil || reservation.SlotNumber() != 1 {
+		t.Fatalf("Reserve() got = %v, error = %v, want slot 1", reservation, err)
+	}
+	if _, err := pl.Reserve("KA-01-HH-9999", Bus, testTime.Add(time.Hour), time.Hour, 0); err == nil {
+		t.Errorf("Reserve() error = %v, wantErr = %v", err, true)
+	}
+
+	// The slot is free for other vehicles until the window starts, then held
+	if count, _ := pl.FreeSlotCount(0); count != 2 {
+		t.Errorf("FreeSlotCount() got = %v, want = 2", count)
+	}
+	clock.Advance(time.Hour)
+	if ticket, err := pl.Park(generateVehicle(1)); err != nil || ticket.Slots()[0].SlotNumber() != 2 {
+		t.Fatalf("Park() got = %v, error = %v, want slot 2", ticket, err)
+	}
+	if _, err := pl.Park(generateVehicle(2)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+
+	// The vehicle with the reservation parks in the held slot
+	ticket, err := pl.Park(generateVehicle(1234))
+	if err != nil || ticket.Slots()[0].SlotNumber() != 1 {
+		t.Fatalf("Park() got = %v, error = %v, want slot 1", ticket, err)
+	}
+	// The reservation returned by Reserve is a copy, so look it up again
+	if reservations, _ := pl.Reservations(); reservations[0].Status() != Fulfilled {
+		t.Errorf("Status() got = %v, want = %v", reservations[0].Status(), Fulfilled)
+	}
+}
+
+func TestReservationNoShow(t *testing.T) {
+	pl, clock := createParkingLotWithClock(t, 1)
+	pl.setNoShowAfter(10 * time.Minute)
+	reservation, err := pl.Reserve("KA-01-HH-1234", Car, testTime, time.Hour, 1)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if reservation.Status() != Held {
+		t.Errorf("Status() got = %v, want = %v", reservation.Status(), Held)
+	}
+	if _, err := pl.Park(generateVehicle(1)); !errors.Is(err, ErrParkingLotFull) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrParkingLotFull)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if _, err := pl.Park(generateVehicle(1)); err != nil {
+		t.Errorf("Park() error = %v", err)
+	}
+	if reservations, _ := pl.Reservations(); reservations[0].Status() != NoShow {
+		t.Errorf("Status() got = %v, want = %v", reservations[0].Status(), NoShow)
+	}
+}
+
+func TestCancelReservation(t *testing.T) {
+	pl, _ := createParkingLotWithClock(t, 1)
+	if _, err := pl.Reserve("KA-01-HH-1234", Car, testTime, time.Hour, 0); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if err := pl.CancelReservation(1); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+	for _, reservationNumber := range []int{1, 2} {
+		if err := pl.CancelReservation(reservationNumber); err == nil {
+			t.Errorf("CancelReservation(%v) error = %v, wantErr = %v", reservationNumber, err, true)
+		}
+	}
+	if _, err := pl.Park(generateVehicle(1)); err != nil {
+		t.Errorf("Park() error = %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package parkinglot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A SlotSize determines which vehicle types fit in a slot
+type SlotSize int
+
+const (
+	Small SlotSize = iota
+	Medium
+	Large
+)
+
+var slotSizeNames = []string{"small", "medium", "large"}
+
+// Parse a slot size name, such as "large"
+func ParseSlotSize(name string) (SlotSize, error) {
+	for i, sizeName := range slotSizeNames {
+		if strings.EqualFold(name, sizeName) {
+			return SlotSize(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown slot size: %v", name)
+}
+
+func (s SlotSize) String() string {
+	return slotSizeNames[s]
+}
+
+// Attributes are the special features of a slot, such as an EV charger
+type Attributes uint8
+
+const (
+	EVCharger Attributes = 1 << iota
+	Accessible
+	Covered
+	Compact
+)
+
+var attributeNames = []string{"ev", "accessible", "covered", "compact"}
+
+// Parse a comma separated list of attribute names, such as "ev,covered"
+func ParseAttributes(input string) (Attributes, error) {
+	var attributes Attributes
+	for _, name := range strings.Split(input, ",") {
+		found := false
+		for i, attributeName := range attributeNames {
+			if strings.EqualFold(name, attributeName) {
+				attributes |= 1 << uint(i)
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("Unknown slot attribute: %v", name)
+		}
+	}
+	return attributes, nil
+}
+
+// Reports whether all of the other attributes are set
+func (a Attributes) has(other Attributes) bool {
+	return a&other == other
+}
+
+func (a Attributes) String() string {
+	var names []string
+	for i, name := range attributeNames {
+		if a.has(1 << uint(i)) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+type Slot struct {
+	vehicle      *Vehicle
+	slotNumber   int
+	floorNumber  int
+	distance     int // Distance from the entry point
+	exitDistance int // Distance to the exit
+	size         SlotSize
+	attributes   Attributes
+	useCount     int // Number of vehicles that have parked in the slot
+	position     int // Position of the slot in the order of its allocator pool
+}
+
+// Park a vehicle at the spot
+func (s *Slot) parkVehicle(v *Vehicle) {
+	s.vehicle = v
+	s.useCount++
+	v.slots = append(v.slots, s)
+}
+
+func (s *Slot) SlotNumber() int {
+	return s.slotNumber
+}
+
+func (s *Slot) FloorNumber() int {
+	return s.floorNumber
+}
+
+func (s *Slot) Distance() int {
+	return s.distance
+}
+
+func (s *Slot) ExitDistance() int {
+	return s.exitDistance
+}
+
+func (s *Slot) UseCount() int {
+	return s.useCount
+}
+
+func (s *Slot) Size() SlotSize {
+	return s.size
+}
+
+func (s *Slot) Attributes() Attributes {
+	return s.attributes
+}
+
+func (s *Slot) Vehicle() *Vehicle {
+	return s.vehicle
+}
+
+func (s *Slot) removeVehicle() {
+	s.vehicle = nil
+}
@@ -0,0 +1,84 @@
+package parkinglot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func compareSlot(t *testing.T, got *Slot, want *Slot) {
+	if !reflect.DeepEqual(got.vehicle, want.vehicle) ||
+		got.slotNumber != want.slotNumber ||
+		got.useCount != want.useCount {
+		t.Errorf("parkVehicle() got = %v, want = %v", got, want)
+	}
+}
+
+func TestSlotParkVehicle(t *testing.T) {
+	type args struct {
+		v *Vehicle
+	}
+	tests := []struct {
+		name string
+		slot *Slot
+		args args
+		want *Slot
+	}{
+		{
+			name: "Park vehicle",
+			slot: &Slot{
+				slotNumber: 1,
+			},
+			args: args{
+				v: &Vehicle{
+					registrationNumber: "park KA-01-HH-1234",
+					color:              "White",
+				},
+			},
+			want: &Slot{
+				vehicle: &Vehicle{
+					registrationNumber: "park KA-01-HH-1234",
+					color:              "White",
+				},
+				slotNumber: 1,
+				useCount:   1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		// The vehicle keeps track of the slot it is parked in
+		tt.want.vehicle.slots = []*Slot{tt.want}
+
+		t.Run(tt.name, func(t *testing.T) {
+			tt.slot.parkVehicle(tt.args.v)
+			compareSlot(t, tt.slot, tt.want)
+		})
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Attributes
+		wantErr bool
+	}{
+		{name: "Single attribute", input: "ev", want: EVCharger},
+		{name: "Several attributes", input: "Covered,accessible", want: Accessible | Covered},
+		{name: "Unknown attribute", input: "ev,valet", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttributes(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAttributes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseAttributes() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
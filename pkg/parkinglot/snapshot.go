@@ -0,0 +1,648 @@
+package parkinglot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/cedrickchee/go-parkinglot/internal/bitset"
+)
+
+// Version of the snapshots written by SaveSnapshot. LoadSnapshot refuses
+// snapshots of any other version.
+const SnapshotVersion = 1
+
+// A snapshot is the state of a parking lot as a JSON document. The options the
+// lot was made with by New, such as its clock, tariff and operator on duty, are
+// not part of it.
+type snapshot struct {
+	Version      int                   `json:"version"`
+	Address      string                `json:"address"`
+	Capacity     int                   `json:"capacity"`
+	SizePolicy   string                `json:"size_policy"`
+	Allocator    string                `json:"allocator"`
+	Random       *randomSnapshot       `json:"random,omitempty"` // State of the random allocator
+	Floors       []floorSnapshot       `json:"floors"`
+	Slots        []slotSnapshot        `json:"slots"` // Ordered by slot number
+	Pools        []poolSnapshot        `json:"pools"`
+	Gates        []gateSnapshot        `json:"gates,omitempty"`
+	Tickets      []ticketSnapshot      `json:"tickets,omitempty"` // Ordered by ticket number
+	History      []eventSnapshot       `json:"history,omitempty"`
+	Waitlist     *[]waitEntrySnapshot  `json:"waitlist,omitempty"` // Nil if vehicles are turned away when full
+	Permits      []permitSnapshot      `json:"permits,omitempty"`
+	PermitPool   int                   `json:"permit_pool,omitempty"`
+	Reservations []reservationSnapshot `json:"reservations,omitempty"` // Ordered by reservation number
+	Overrides    []overrideSnapshot    `json:"overrides,omitempty"`
+}
+
+type randomSnapshot struct {
+	Seed  int64 `json:"seed"`
+	Draws int   `json:"draws"`
+}
+
+type floorSnapshot struct {
+	Capacity int `json:"capacity"`
+	Distance int `json:"distance"`
+}
+
+type slotSnapshot struct {
+	Distance     int    `json:"distance"`
+	ExitDistance int    `json:"exit_distance"`
+	Size         string `json:"size"`
+	Attributes   string `json:"attributes,omitempty"`
+	UseCount     int    `json:"use_count,omitempty"`
+	Rank         int    `json:"rank"` // Rank the allocator gave the slot when the lot was created
+}
+
+// The free slots of a pool. The slots in rank order from highest_slot on have
+// never been handed out and are all free, the free slots before it are listed
+// with the rank they were freed with.
+type poolSnapshot struct {
+	Size        string             `json:"size"`
+	Attributes  string             `json:"attributes,omitempty"`
+	HighestSlot int                `json:"highest_slot"`
+	Free        []freeSlotSnapshot `json:"free,omitempty"`
+}
+
+type freeSlotSnapshot struct {
+	Slot int `json:"slot"`
+	Rank int `json:"rank"`
+}
+
+type gateSnapshot struct {
+	Name      string `json:"name"`
+	Distances []int  `json:"distances"`
+}
+
+type vehicleSnapshot struct {
+	RegistrationNumber string          `json:"registration_number"`
+	Color              string          `json:"colour"`
+	Type               string          `json:"type"`
+	Needs              string          `json:"needs,omitempty"`
+	Permit             *permitSnapshot `json:"permit,omitempty"` // Permit valid when the vehicle arrived
+}
+
+type ticketSnapshot struct {
+	Vehicle   vehicleSnapshot `json:"vehicle"`
+	Slots     []int           `json:"slots"`
+	EntryTime time.Time       `json:"entry_time"`
+	ExitTime  *time.Time      `json:"exit_time,omitempty"` // Nil while the ticket is open
+	Fee       int64           `json:"fee,omitempty"`
+	Assigned  []int           `json:"assigned,omitempty"` // Ticket numbers of the waiting vehicles given the freed slots
+}
+
+type eventSnapshot struct {
+	Type               string    `json:"type"`
+	RegistrationNumber string    `json:"registration_number"`
+	Color              string    `json:"colour"`
+	Slots              []int     `json:"slots"`
+	Ticket             int       `json:"ticket"`
+	Time               time.Time `json:"time"`
+	Operator           string    `json:"operator,omitempty"`
+}
+
+type waitEntrySnapshot struct {
+	Vehicle  vehicleSnapshot `json:"vehicle"`
+	Gate     string          `json:"gate,omitempty"`
+	Priority bool            `json:"priority,omitempty"`
+	Since    time.Time       `json:"since"`
+}
+
+type permitSnapshot struct {
+	RegistrationNumber string    `json:"registration_number"`
+	ValidFrom          time.Time `json:"valid_from"`
+	ValidUntil         time.Time `json:"valid_until"`
+	Slot               int       `json:"slot,omitempty"`
+	Dedicated          bool      `json:"dedicated,omitempty"` // The slot is still dedicated to the permit
+	Held               bool      `json:"held,omitempty"`      // The slot is held out of allocation for the permit
+}
+
+type reservationSnapshot struct {
+	RegistrationNumber string    `json:"registration_number"`
+	Slot               int       `json:"slot"`
+	Start              time.Time `json:"start"`
+	End                time.Time `json:"end"`
+	Status             string    `json:"status"`
+}
+
+type overrideSnapshot struct {
+	Ticket int       `json:"ticket"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// Write the state of the parking lot as a versioned JSON document. A lot
+// restored from it by LoadSnapshot hands out the same slots as this one would
+// have, the random allocator included.
+func (pl *ParkingLot) SaveSnapshot(w io.Writer) error {
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+
+	if err := pl.isCreated(); err != nil {
+		return err
+	}
+	allocator, ok := allocatorName(pl.allocator.strategy)
+	if !ok {
+		return ErrAllocatorNotSaved
+	}
+
+	s := &snapshot{
+		Version:    SnapshotVersion,
+		Address:    pl.address,
+		Capacity:   pl.capacity,
+		SizePolicy: pl.allocator.policy.String(),
+		Allocator:  allocator,
+		PermitPool: pl.permits.pool,
+	}
+	if r, ok := pl.allocator.strategy.(*random); ok {
+		s.Random = &randomSnapshot{Seed: r.seed, Draws: r.draws}
+	}
+	for _, floor := range pl.floors {
+		s.Floors = append(s.Floors, floorSnapshot{Capacity: floor.Capacity(), Distance: floor.distance})
+	}
+
+	for _, pool := range pl.allocator.pools {
+		s.Pools = append(s.Pools, snapshotPool(pool))
+	}
+	for _, slot := range pl.slots {
+		s.Slots = append(s.Slots, slotSnapshot{
+			Distance:     slot.distance,
+			ExitDistance: slot.exitDistance,
+			Size:         slot.size.String(),
+			Attributes:   slot.attributes.String(),
+			UseCount:     slot.useCount,
+			Rank:         pl.allocator.getPool(slot).getRank(slot.position),
+		})
+	}
+
+	gates := make([]gateSnapshot, len(pl.allocator.gates))
+	for name, gate := range pl.allocator.gates {
+		gates[gate] = gateSnapshot{Name: name, Distances: pl.allocator.pools[0].gates[gate].distances}
+	}
+	s.Gates = gates
+
+	for _, ticket := range pl.tickets {
+		t := ticketSnapshot{
+			Vehicle:   snapshotVehicle(ticket.vehicle),
+			Slots:     getSlotNumbers(ticket.Slots()),
+			EntryTime: ticket.entryTime,
+			Fee:       ticket.fee,
+		}
+		if ticket.IsClosed() {
+			exitTime := ticket.exitTime
+			t.ExitTime = &exitTime
+		}
+		for _, assigned := range ticket.assigned {
+			t.Assigned = append(t.Assigned, assigned.TicketNumber())
+		}
+		s.Tickets = append(s.Tickets, t)
+	}
+
+	for _, event := range pl.history.events {
+		s.History = append(s.History, eventSnapshot{
+			Type:               event.eventType.String(),
+			RegistrationNumber: event.registrationNumber,
+			Color:              event.color,
+			Slots:              event.slotNumbers,
+			Ticket:             event.ticketNumber,
+			Time:               event.time,
+			Operator:           event.operator,
+		})
+	}
+
+	if pl.waitlist != nil {
+		entries := []waitEntrySnapshot{}
+		for _, entry := range pl.waitlist.getEntries() {
+			entries = append(entries, waitEntrySnapshot{
+				Vehicle:  snapshotVehicle(entry.vehicle),
+				Gate:     entry.gate,
+				Priority: entry.priority,
+				Since:    entry.since,
+			})
+		}
+		s.Waitlist = &entries
+	}
+
+	for _, permit := range pl.permits.getPermits() {
+		p := snapshotPermit(permit)
+		p.Dedicated = !permit.IsFloating() && pl.permits.getDedicated(permit.SlotNumber()) == permit
+		p.Held = p.Dedicated && pl.permits.isHeld(permit.SlotNumber())
+		s.Permits = append(s.Permits, p)
+	}
+
+	for _, reservation := range pl.reservations.reservations {
+		s.Reservations = append(s.Reservations, reservationSnapshot{
+			RegistrationNumber: reservation.registrationNumber,
+			Slot:               reservation.slotNumber,
+			Start:              reservation.start,
+			End:                reservation.end,
+			Status:             reservation.status.String(),
+		})
+	}
+
+	for _, override := range pl.overrides {
+		s.Overrides = append(s.Overrides, overrideSnapshot{
+			Ticket: override.ticket.TicketNumber(),
+			Reason: override.reason,
+			Time:   override.time,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Get the free slots of a pool that have been handed out before, by slot number
+func snapshotPool(pool *slotPool) poolSnapshot {
+	p := poolSnapshot{
+		Size:        pool.size.String(),
+		Attributes:  pool.attributes.String(),
+		HighestSlot: pool.highestSlot,
+	}
+	if pool.compact != nil {
+		for i := 0; i < pool.highestSlot; i++ {
+			if pool.compact.Contains(i) {
+				p.Free = append(p.Free, freeSlotSnapshot{Slot: pool.order[i].SlotNumber(), Rank: pool.getRank(i)})
+			}
+		}
+		return p
+	}
+	for slotNumber, key := range pool.keys {
+		p.Free = append(p.Free, freeSlotSnapshot{Slot: slotNumber, Rank: key.rank})
+	}
+	sort.Slice(p.Free, func(i, j int) bool {
+		return p.Free[i].Slot < p.Free[j].Slot
+	})
+	return p
+}
+
+func snapshotVehicle(vehicle *Vehicle) vehicleSnapshot {
+	v := vehicleSnapshot{
+		RegistrationNumber: vehicle.registrationNumber,
+		Color:              vehicle.color,
+		Type:               vehicle.vehicleType.String(),
+		Needs:              vehicle.needs.String(),
+	}
+	if vehicle.permit != nil {
+		p := snapshotPermit(vehicle.permit)
+		v.Permit = &p
+	}
+	return v
+}
+
+func snapshotPermit(permit *Permit) permitSnapshot {
+	return permitSnapshot{
+		RegistrationNumber: permit.registrationNumber,
+		ValidFrom:          permit.validFrom,
+		ValidUntil:         permit.validUntil,
+		Slot:               permit.slotNumber,
+	}
+}
+
+func getSlotNumbers(slots []*Slot) []int {
+	var slotNumbers []int
+	for _, slot := range slots {
+		slotNumbers = append(slotNumbers, slot.SlotNumber())
+	}
+	return slotNumbers
+}
+
+// Restore the parking lot from a snapshot written by SaveSnapshot. The lot
+// must not have been created yet, and keeps the options it was made with. A
+// snapshot that had a waitlist gives the lot one.
+func (pl *ParkingLot) LoadSnapshot(r io.Reader) error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	if err := pl.isCreated(); err == nil {
+		return ErrAlreadyCreated
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var version struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &version); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if version.Version != SnapshotVersion {
+		return fmt.Errorf("%w: %v", ErrSnapshotVersion, version.Version)
+	}
+	s := &snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	restored, err := restoreSnapshot(s)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	pl.address = restored.address
+	pl.capacity = restored.capacity
+	pl.floors = restored.floors
+	pl.slots = restored.slots
+	pl.allocator = restored.allocator
+	pl.tickets = restored.tickets
+	pl.index = restored.index
+	pl.history = restored.history
+	pl.permits = restored.permits
+	pl.reservations = restored.reservations
+	pl.overrides = restored.overrides
+	if restored.waitlist != nil {
+		pl.waitlist = restored.waitlist
+	}
+	return nil
+}
+
+// Build the state of a parking lot from a snapshot. Returns an error saying
+// what is wrong with a snapshot that does not describe a consistent lot.
+func restoreSnapshot(s *snapshot) (*ParkingLot, error) {
+	pl := &ParkingLot{
+		address:      s.Address,
+		capacity:     len(s.Slots),
+		index:        newVehicleIndex(),
+		permits:      newPermitRegistry(),
+		reservations: newReservationBook(),
+	}
+	if pl.capacity == 0 || s.Capacity != pl.capacity {
+		return nil, fmt.Errorf("capacity %v does not match %v slots", s.Capacity, len(s.Slots))
+	}
+	isSlot := func(slotNumber int) bool {
+		return slotNumber > 0 && slotNumber <= pl.capacity
+	}
+
+	// Slots, numbered consecutively across floors
+	var ranks []int
+	for i, f := range s.Floors {
+		if f.Capacity <= 0 {
+			return nil, fmt.Errorf("floor %v: %v", i+1, ErrInvalidCapacity)
+		}
+		floor := &Floor{floorNumber: i + 1, distance: f.Distance}
+		for j := 0; j < f.Capacity; j++ {
+			n := len(pl.slots) + 1
+			if !isSlot(n) {
+				return nil, fmt.Errorf("floors have more slots than %v", pl.capacity)
+			}
+			saved := s.Slots[n-1]
+			size, err := ParseSlotSize(saved.Size)
+			if err != nil {
+				return nil, err
+			}
+			attributes, err := parseSnapshotAttributes(saved.Attributes)
+			if err != nil {
+				return nil, err
+			}
+			slot := &Slot{
+				slotNumber:   n,
+				floorNumber:  floor.floorNumber,
+				distance:     saved.Distance,
+				exitDistance: saved.ExitDistance,
+				size:         size,
+				attributes:   attributes,
+				useCount:     saved.UseCount,
+			}
+			ranks = append(ranks, saved.Rank)
+			floor.slots = append(floor.slots, slot)
+			pl.slots = append(pl.slots, slot)
+		}
+		pl.floors = append(pl.floors, floor)
+	}
+	if len(pl.slots) != pl.capacity {
+		return nil, fmt.Errorf("floors have %v slots, want %v", len(pl.slots), pl.capacity)
+	}
+
+	// Allocator, with the free slots of each pool
+	policy, err := ParseSizePolicy(s.SizePolicy)
+	if err != nil {
+		return nil, err
+	}
+	newAllocator, ok := allocators[s.Allocator]
+	if !ok {
+		return nil, fmt.Errorf("unknown allocator %v", s.Allocator)
+	}
+	strategy := newAllocator(pl.slots)
+	if _, ok := strategy.(*random); ok && s.Random != nil {
+		strategy = restoreRandom(s.Random.Seed, s.Random.Draws)
+	}
+	pl.allocator = newRankedSlotAllocator(pl.slots, policy, strategy, ranks)
+	if len(s.Pools) != len(pl.allocator.pools) {
+		return nil, fmt.Errorf("%v pools do not match %v pools of the slots", len(s.Pools), len(pl.allocator.pools))
+	}
+	free := make(map[int]bool)
+	for _, saved := range s.Pools {
+		if err := restorePool(pl.allocator, saved, free); err != nil {
+			return nil, err
+		}
+	}
+	for _, slot := range pl.slots {
+		if !free[slot.SlotNumber()] {
+			pl.allocator.free.Remove(slot.SlotNumber() - 1)
+		}
+	}
+	for _, gate := range s.Gates {
+		if _, ok := pl.allocator.gates[gate.Name]; ok || len(gate.Distances) != pl.capacity {
+			return nil, fmt.Errorf("gate %v", gate.Name)
+		}
+		pl.allocator.addGate(gate.Name, gate.Distances)
+	}
+
+	// Permits, before the vehicles that arrived with them
+	for _, saved := range s.Permits {
+		if saved.Slot != 0 && !isSlot(saved.Slot) {
+			return nil, fmt.Errorf("permit of %v: %v", saved.RegistrationNumber, ErrInvalidSlot)
+		}
+		// An expired permit may share its slot with the permit that now holds it
+		permit := restorePermit(saved)
+		pl.permits.permits[permit.registrationNumber] = permit
+		if saved.Dedicated && !permit.IsFloating() {
+			pl.permits.dedicated[permit.slotNumber] = permit
+			if saved.Held {
+				pl.permits.hold(permit.slotNumber)
+			}
+		}
+	}
+	if s.PermitPool < 0 {
+		return nil, ErrNegativePermitPool
+	}
+	pl.permits.pool = s.PermitPool
+
+	// Tickets and the vehicles still parked
+	for i, saved := range s.Tickets {
+		vehicle, err := pl.restoreVehicle(saved.Vehicle)
+		if err != nil {
+			return nil, err
+		}
+		if len(saved.Slots) == 0 {
+			return nil, fmt.Errorf("ticket %v has no slots", i+1)
+		}
+		for _, slotNumber := range saved.Slots {
+			if !isSlot(slotNumber) {
+				return nil, fmt.Errorf("ticket %v: %v", i+1, &SlotError{SlotNumber: slotNumber, Err: ErrInvalidSlot})
+			}
+			vehicle.slots = append(vehicle.slots, pl.slots[slotNumber-1])
+		}
+		ticket := issueTicket(i+1, vehicle, saved.EntryTime)
+		vehicle.ticket = ticket
+		vehicle.entryTime = saved.EntryTime
+		pl.tickets = append(pl.tickets, ticket)
+		if saved.ExitTime != nil {
+			ticket.close(*saved.ExitTime, saved.Fee)
+			continue
+		}
+		for _, slot := range vehicle.slots {
+			if slot.vehicle != nil || free[slot.SlotNumber()] {
+				return nil, fmt.Errorf("ticket %v: %v", i+1, &SlotError{SlotNumber: slot.SlotNumber(), Err: ErrSlotOccupied})
+			}
+			slot.vehicle = vehicle
+		}
+		pl.index.add(vehicle)
+	}
+	for i, saved := range s.Tickets {
+		for _, ticketNumber := range saved.Assigned {
+			if ticketNumber <= 0 || ticketNumber > len(pl.tickets) {
+				return nil, fmt.Errorf("ticket %v: %v", i+1, &TicketError{TicketNumber: ticketNumber, Err: ErrTicketNotFound})
+			}
+			pl.tickets[i].assigned = append(pl.tickets[i].assigned, pl.tickets[ticketNumber-1])
+		}
+	}
+
+	for _, saved := range s.History {
+		eventType, err := parseEventType(saved.Type)
+		if err != nil {
+			return nil, err
+		}
+		pl.history.record(&Event{
+			eventType:          eventType,
+			registrationNumber: saved.RegistrationNumber,
+			color:              saved.Color,
+			slotNumbers:        saved.Slots,
+			ticketNumber:       saved.Ticket,
+			time:               saved.Time,
+			operator:           saved.Operator,
+		})
+	}
+
+	if s.Waitlist != nil {
+		pl.enableWaitlist()
+		for _, saved := range *s.Waitlist {
+			vehicle, err := pl.restoreVehicle(saved.Vehicle)
+			if err != nil {
+				return nil, err
+			}
+			pl.waitlist.add(&WaitEntry{vehicle: vehicle, gate: saved.Gate, priority: saved.Priority, since: saved.Since})
+		}
+	}
+
+	for i, saved := range s.Reservations {
+		if !isSlot(saved.Slot) {
+			return nil, fmt.Errorf("reservation %v: %v", i+1, &SlotError{SlotNumber: saved.Slot, Err: ErrInvalidSlot})
+		}
+		status, err := parseReservationStatus(saved.Status)
+		if err != nil {
+			return nil, err
+		}
+		reservation := &Reservation{
+			registrationNumber: saved.RegistrationNumber,
+			slotNumber:         saved.Slot,
+			start:              saved.Start,
+			end:                saved.End,
+		}
+		pl.reservations.add(reservation)
+		pl.reservations.setStatus(reservation, status)
+	}
+
+	for _, saved := range s.Overrides {
+		if saved.Ticket <= 0 || saved.Ticket > len(pl.tickets) {
+			return nil, &TicketError{TicketNumber: saved.Ticket, Err: ErrTicketNotFound}
+		}
+		pl.overrides = append(pl.overrides, &Override{ticket: pl.tickets[saved.Ticket-1], reason: saved.Reason, time: saved.Time})
+	}
+
+	return pl, nil
+}
+
+// Restore the free slots of the pool of a slot allocator with the given size
+// and attributes. Adds the numbers of the free slots to free.
+func restorePool(a *slotAllocator, saved poolSnapshot, free map[int]bool) error {
+	size, err := ParseSlotSize(saved.Size)
+	if err != nil {
+		return err
+	}
+	attributes, err := parseSnapshotAttributes(saved.Attributes)
+	if err != nil {
+		return err
+	}
+	var pool *slotPool
+	for _, p := range a.pools {
+		if p.size == size && p.attributes == attributes {
+			pool = p
+		}
+	}
+	if pool == nil || pool.highestSlot != 0 || saved.HighestSlot < 0 || saved.HighestSlot > len(pool.order) {
+		return fmt.Errorf("pool of %v slots with attributes %q", saved.Size, saved.Attributes)
+	}
+
+	pool.highestSlot = saved.HighestSlot
+	if pool.compact != nil {
+		pool.compact = bitset.New(len(pool.order))
+		for i := pool.highestSlot; i < len(pool.order); i++ {
+			pool.compact.Add(i)
+		}
+	}
+	for _, slot := range pool.order[pool.highestSlot:] {
+		free[slot.SlotNumber()] = true
+	}
+	for _, f := range saved.Free {
+		if f.Slot <= 0 || f.Slot > len(a.slots) || a.getPool(a.slots[f.Slot-1]) != pool ||
+			a.slots[f.Slot-1].position >= pool.highestSlot || free[f.Slot] {
+			return fmt.Errorf("free slot %v of the pool of %v slots", f.Slot, saved.Size)
+		}
+		pool.push(a.slots[f.Slot-1], f.Rank)
+		free[f.Slot] = true
+	}
+	return nil
+}
+
+// Restore a vehicle, and the permit it arrived with. A permit still in the
+// registry is shared with it, as when the vehicle arrived.
+func (pl *ParkingLot) restoreVehicle(saved vehicleSnapshot) (*Vehicle, error) {
+	vehicleType, err := ParseVehicleType(saved.Type)
+	if err != nil {
+		return nil, err
+	}
+	needs, err := parseSnapshotAttributes(saved.Needs)
+	if err != nil {
+		return nil, err
+	}
+	vehicle := NewVehicle(saved.RegistrationNumber, saved.Color, vehicleType, needs)
+	if saved.Permit != nil {
+		vehicle.permit = restorePermit(*saved.Permit)
+		if permit, ok := pl.permits.get(saved.RegistrationNumber); ok && isSamePermit(permit, vehicle.permit) {
+			vehicle.permit = permit
+		}
+	}
+	return vehicle, nil
+}
+
+func restorePermit(saved permitSnapshot) *Permit {
+	return createPermit(saved.RegistrationNumber, saved.ValidFrom, saved.ValidUntil, saved.Slot)
+}
+
+// Reports whether two permits are for the same registration number, days and slot
+func isSamePermit(a, b *Permit) bool {
+	return a.registrationNumber == b.registrationNumber && a.validFrom.Equal(b.validFrom) &&
+		a.validUntil.Equal(b.validUntil) && a.slotNumber == b.slotNumber
+}
+
+// Parse the attributes of a slot or the needs of a vehicle, empty for none
+func parseSnapshotAttributes(input string) (Attributes, error) {
+	if input == "" {
+		return 0, nil
+	}
+	return ParseAttributes(input)
+}
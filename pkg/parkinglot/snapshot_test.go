@@ -0,0 +1,278 @@
+package parkinglot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Floors of mixed slot sizes and attributes. Both floors start at the entry
+// point, so that their slots share distances and ranks.
+var snapshotLayouts = []FloorLayout{
+	{Capacity: 8, Sizes: []SlotSize{Small, Small}, Attributes: []Attributes{0, 0, EVCharger}},
+	{Capacity: 8, ExitDistance: 2, Sizes: []SlotSize{Medium, Medium, Large, Large, Large}},
+}
+
+// Create a parking lot with gates, permits, reservations, a waitlist and an
+// override, and park and leave a while
+func newSnapshotLot(t *testing.T, name string, layouts []FloorLayout) *ParkingLot {
+	t.Helper()
+	newAllocator, err := LookupAllocator(name)
+	if err != nil {
+		t.Fatalf("LookupAllocator() error = %v", err)
+	}
+	pl := New(&Options{Clock: NewFakeClock(testTime), Operator: "alice", Waitlist: true})
+	if err := pl.Create("Marina Bay Sands", layouts, &CreateOptions{SizePolicy: AllowLarger, Allocator: newAllocator}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := pl.AddGate("North", GateDistances(pl.Slots(), pl.Capacity())); err != nil {
+		t.Fatalf("AddGate() error = %v", err)
+	}
+	if _, err := pl.AddPermit("KA-01-PP-0001", testTime, testTime.AddDate(0, 0, 30), 4); err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	if _, err := pl.AddPermit("KA-01-PP-0002", testTime, testTime.AddDate(0, 0, 30), 0); err != nil {
+		t.Fatalf("AddPermit() error = %v", err)
+	}
+	if err := pl.SetPermitPool(1); err != nil {
+		t.Fatalf("SetPermitPool() error = %v", err)
+	}
+	if _, err := pl.Reserve("KA-01-RR-0001", Car, testTime.Add(time.Minute), time.Hour, 0); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err := pl.Reserve("KA-01-RR-0002", Car, testTime.AddDate(0, 0, 1), time.Hour, 0); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	churn(pl, rand.New(rand.NewSource(1)), 0, 60)
+	if _, err := pl.OverridePark(NewVehicle("KA-01-HH-0003", "Black", Motorcycle, 0), "", "Cloned plate"); err != nil &&
+		!errors.Is(err, ErrParkingLotFull) {
+		t.Fatalf("OverridePark() error = %v", err)
+	}
+	// Fill the parking lot until a vehicle has to wait
+	for i := 0; ; i++ {
+		_, err := pl.Park(NewVehicle(fmt.Sprintf("KA-01-WW-%04d", i), "Red", Car, 0))
+		if errors.Is(err, ErrWaitlisted) {
+			return pl
+		}
+	}
+}
+
+// Park vehicles of every type and let them leave at random, and return what
+// happened at each step. Parking lots in the same state given the same source
+// of random numbers do the same.
+func churn(pl *ParkingLot, r *rand.Rand, first, steps int) []string {
+	types := []VehicleType{Motorcycle, Car, Car, Car, Van, Bus}
+	gates := []string{"", "", "North"}
+	var log []string
+	for i := first; i < first+steps; i++ {
+		pl.Clock().(Advancer).Advance(time.Duration(r.Intn(10)) * time.Minute)
+		if parked := pl.Status(); len(parked) > 0 && r.Intn(2) == 0 {
+			ticket, err := pl.Leave(parked[r.Intn(len(parked))].SlotNumber())
+			if err != nil {
+				log = append(log, fmt.Sprintf("leave: %v", err))
+				continue
+			}
+			var assigned []string
+			for _, t := range ticket.Assigned() {
+				assigned = append(assigned, fmt.Sprint(t.TicketNumber(), getSlotNumbers(t.Slots())))
+			}
+			log = append(log, fmt.Sprintf("leave %v: %v", ticket.TicketNumber(), assigned))
+			continue
+		}
+		vehicle := NewVehicle(fmt.Sprintf("KA-01-HH-%04d", i), "White", types[r.Intn(len(types))], 0)
+		if r.Intn(10) == 0 {
+			vehicle = NewVehicle(fmt.Sprintf("KA-01-PP-%04d", 1+r.Intn(2)), "Blue", Car, 0)
+		}
+		ticket, err := pl.ParkAtGate(vehicle, gates[r.Intn(len(gates))])
+		if err != nil {
+			log = append(log, fmt.Sprintf("park %v: %v", vehicle.RegistrationNumber(), err))
+			continue
+		}
+		log = append(log, fmt.Sprintf("park %v: %v %v", vehicle.RegistrationNumber(), ticket.TicketNumber(), getSlotNumbers(ticket.Slots())))
+	}
+	return log
+}
+
+func saveSnapshot(t *testing.T, pl *ParkingLot) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pl.SaveSnapshot(&buf); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSnapshot(t *testing.T) {
+	sameDistances := []FloorLayout{{Capacity: 6}, {Capacity: 6, ExitDistance: 6}}
+
+	tests := []struct {
+		name      string
+		allocator string
+		layouts   []FloorLayout
+	}{
+		{name: "Nearest entry in bitsets", allocator: "nearest_entry", layouts: StackedFloors([]int{8, 8})},
+		{name: "Nearest entry in heaps", allocator: "nearest_entry", layouts: sameDistances},
+		{name: "Nearest exit", allocator: "nearest_exit", layouts: snapshotLayouts},
+		{name: "Fill from back", allocator: "fill_from_back", layouts: snapshotLayouts},
+		{name: "Wear levelling", allocator: "wear_levelling", layouts: snapshotLayouts},
+		{name: "Random", allocator: "random", layouts: snapshotLayouts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := newSnapshotLot(t, tt.allocator, tt.layouts)
+			saved := saveSnapshot(t, pl)
+
+			restored := New(&Options{Clock: NewFakeClock(pl.Clock().Now()), Operator: "bob"})
+			if err := restored.LoadSnapshot(bytes.NewReader(saved)); err != nil {
+				t.Fatalf("LoadSnapshot() error = %v", err)
+			}
+			if got := saveSnapshot(t, restored); !bytes.Equal(got, saved) {
+				t.Fatalf("SaveSnapshot() of the restored lot got = %s, want = %s", got, saved)
+			}
+
+			// The restored lot hands out the same slots as the original
+			want := churn(pl, rand.New(rand.NewSource(2)), 1000, 80)
+			got := churn(restored, rand.New(rand.NewSource(2)), 1000, 80)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("restored lot got = %v\nwant = %v", got, want)
+			}
+			if w, g := pl.Status(), restored.Status(); len(g) != len(w) {
+				t.Errorf("Status() got %v vehicles, want = %v", len(g), len(w))
+			}
+		})
+	}
+}
+
+func TestSnapshotQueries(t *testing.T) {
+	pl := newSnapshotLot(t, "nearest_entry", snapshotLayouts)
+	restored := New(&Options{Clock: NewFakeClock(pl.Clock().Now())})
+	if err := restored.LoadSnapshot(bytes.NewReader(saveSnapshot(t, pl))); err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	if got, want := len(restored.Floors()), len(pl.Floors()); got != want {
+		t.Errorf("Floors() got = %v, want = %v", got, want)
+	}
+	for _, slot := range pl.Slots() {
+		got := restored.Slot(slot.SlotNumber())
+		if got.FloorNumber() != slot.FloorNumber() || got.Distance() != slot.Distance() || got.Size() != slot.Size() ||
+			got.Attributes() != slot.Attributes() || got.UseCount() != slot.UseCount() {
+			t.Errorf("Slot(%v) got = %+v, want = %+v", slot.SlotNumber(), got, slot)
+		}
+	}
+	for n := 1; n <= 3; n++ {
+		want, _ := pl.Ticket(n)
+		got, err := restored.Ticket(n)
+		if err != nil || got.Vehicle().RegistrationNumber() != want.Vehicle().RegistrationNumber() ||
+			!got.EntryTime().Equal(want.EntryTime()) || got.IsClosed() != want.IsClosed() {
+			t.Errorf("Ticket(%v) got = %v, %v", n, got, err)
+		}
+	}
+	if got, want := restored.Overrides(), pl.Overrides(); len(got) != len(want) {
+		t.Errorf("Overrides() got = %v, want = %v", len(got), len(want))
+	}
+	gotEvents, _ := restored.HistoryForSlot(1)
+	wantEvents, _ := pl.HistoryForSlot(1)
+	if len(gotEvents) != len(wantEvents) || gotEvents[0].Operator() != "alice" {
+		t.Errorf("HistoryForSlot() got = %v, want = %v", gotEvents, wantEvents)
+	}
+	gotPermits, _ := restored.Permits()
+	if len(gotPermits) != 2 || gotPermits[0].SlotNumber() != 4 {
+		t.Errorf("Permits() got = %v", gotPermits)
+	}
+	gotReservations, _ := restored.Reservations()
+	wantReservations, _ := pl.Reservations()
+	for i := range wantReservations {
+		if gotReservations[i].Status() != wantReservations[i].Status() {
+			t.Errorf("Reservations() got status = %v, want = %v", gotReservations[i].Status(), wantReservations[i].Status())
+		}
+	}
+	if _, err := restored.Waitlist(); err != nil {
+		t.Errorf("Waitlist() error = %v, want the waitlist of the snapshot", err)
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	if err := New(nil).SaveSnapshot(&bytes.Buffer{}); !errors.Is(err, ErrNotCreated) {
+		t.Errorf("SaveSnapshot() before Create() error = %v, want = %v", err, ErrNotCreated)
+	}
+	custom := New(nil)
+	custom.Create("Marina Bay Sands", StackedFloors([]int{2}), &CreateOptions{
+		Allocator: func(slots []*Slot) Allocator { return struct{ Allocator }{nearestExit{}} },
+	})
+	if err := custom.SaveSnapshot(&bytes.Buffer{}); !errors.Is(err, ErrAllocatorNotSaved) {
+		t.Errorf("SaveSnapshot() with a custom allocator error = %v, want = %v", err, ErrAllocatorNotSaved)
+	}
+
+	pl := New(nil)
+	if err := pl.Create("Marina Bay Sands", StackedFloors([]int{2}), nil); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := pl.Park(generateVehicle(1)); err != nil {
+		t.Fatalf("Park() error = %v", err)
+	}
+	saved := string(saveSnapshot(t, pl))
+
+	tests := []struct {
+		name     string
+		lot      *ParkingLot
+		snapshot string
+		wantErr  error
+	}{
+		{
+			name:     "Load into a created parking lot",
+			lot:      pl,
+			snapshot: saved,
+			wantErr:  ErrAlreadyCreated,
+		},
+		{
+			name:     "Load a snapshot of another version",
+			snapshot: strings.Replace(saved, `"version": 1`, `"version": 2`, 1),
+			wantErr:  ErrSnapshotVersion,
+		},
+		{
+			name:     "Load a truncated snapshot",
+			snapshot: saved[:len(saved)/2],
+			wantErr:  ErrInvalidSnapshot,
+		},
+		{
+			name: "Load a snapshot with a ticket for a missing slot",
+			snapshot: strings.Replace(saved, `"slots": [
+        1
+      ]`, `"slots": [
+        3
+      ]`, 1),
+			wantErr: ErrInvalidSnapshot,
+		},
+		{
+			name:     "Load a snapshot with a vehicle in a free slot",
+			snapshot: strings.Replace(saved, `"highest_slot": 1`, `"highest_slot": 0`, 1),
+			wantErr:  ErrInvalidSnapshot,
+		},
+		{
+			name:     "Load a snapshot with an unknown allocator",
+			snapshot: strings.Replace(saved, `"nearest_entry"`, `"nearest_moon"`, 1),
+			wantErr:  ErrInvalidSnapshot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lot := tt.lot
+			if lot == nil {
+				lot = New(nil)
+			}
+			err := lot.LoadSnapshot(strings.NewReader(tt.snapshot))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadSnapshot() error = %v, want = %v", err, tt.wantErr)
+			}
+			if tt.lot == nil && lot.Capacity() != 0 {
+				t.Errorf("LoadSnapshot() of a bad snapshot got capacity = %v, want = %v", lot.Capacity(), 0)
+			}
+		})
+	}
+}
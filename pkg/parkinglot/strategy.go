@@ -0,0 +1,148 @@
+package parkinglot
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An Allocator is a strategy for choosing among the free slots that fit a
+// vehicle. Free slots are handed out in ascending order of rank, ties go to
+// the least worn slot, then to the lowest floor and slot number.
+type Allocator interface {
+	// Returns the rank of a free slot. The rank of a slot may only change
+	// while the slot is occupied.
+	Rank(slot *Slot) int
+}
+
+// A staticAllocator never changes the rank of a slot, so the free slots of a
+// pool can be found by their position in rank order alone
+type staticAllocator interface {
+	Allocator
+	isStatic()
+}
+
+// A NewAllocator creates an allocator for the slots of a parking lot
+type NewAllocator func(slots []*Slot) Allocator
+
+// Built-in allocators by name
+var allocators = map[string]NewAllocator{
+	"nearest_entry":  func(slots []*Slot) Allocator { return nearestEntry{} },
+	"nearest_exit":   func(slots []*Slot) Allocator { return nearestExit{} },
+	"fill_from_back": func(slots []*Slot) Allocator { return fillFromBack{} },
+	"round_robin":    newWearLevelling,
+	"wear_levelling": newWearLevelling,
+	"random":         func(slots []*Slot) Allocator { return newRandom(time.Now().UnixNano()) },
+}
+
+// The allocator used unless another one is given
+const DefaultAllocator = "nearest_entry"
+
+// Look up a built-in allocator by name, such as "round_robin"
+func LookupAllocator(name string) (NewAllocator, error) {
+	create, ok := allocators[strings.ToLower(name)]
+	if !ok {
+		var names []string
+		for name := range allocators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Unknown allocator: %v, use one of %v", name, strings.Join(names, ", "))
+	}
+	return create, nil
+}
+
+// Returns the name of a built-in allocator. Reports false for any other
+// allocator.
+func allocatorName(strategy Allocator) (string, bool) {
+	switch strategy.(type) {
+	case nearestEntry:
+		return "nearest_entry", true
+	case nearestExit:
+		return "nearest_exit", true
+	case fillFromBack:
+		return "fill_from_back", true
+	case wearLevelling:
+		return "wear_levelling", true
+	case *random:
+		return "random", true
+	}
+	return "", false
+}
+
+// Hands out the slot nearest to the entry point first
+type nearestEntry struct{}
+
+func (nearestEntry) Rank(slot *Slot) int {
+	return slot.Distance()
+}
+
+func (nearestEntry) isStatic() {}
+
+// Hands out the slot nearest to the exit first
+type nearestExit struct{}
+
+func (nearestExit) Rank(slot *Slot) int {
+	return slot.ExitDistance()
+}
+
+func (nearestExit) isStatic() {}
+
+// Hands out the slot with the highest slot number first
+type fillFromBack struct{}
+
+func (fillFromBack) Rank(slot *Slot) int {
+	return -slot.SlotNumber()
+}
+
+func (fillFromBack) isStatic() {}
+
+// Hands out the least used slot first, nearest to the entry point among
+// equally used slots. While the parking lot is mostly empty this goes round
+// the slots in turn.
+type wearLevelling struct {
+	scale int // Greater than the distance of any slot
+}
+
+func newWearLevelling(slots []*Slot) Allocator {
+	scale := 1
+	for _, slot := range slots {
+		if slot.Distance() >= scale {
+			scale = slot.Distance() + 1
+		}
+	}
+	return wearLevelling{scale: scale}
+}
+
+func (w wearLevelling) Rank(slot *Slot) int {
+	return slot.UseCount()*w.scale + slot.Distance()
+}
+
+// Hands out a free slot at random. The seed and the number of ranks drawn
+// are kept, so that a snapshot can restore the allocator to the same state.
+type random struct {
+	rand  *rand.Rand
+	seed  int64
+	draws int
+}
+
+func newRandom(seed int64) Allocator {
+	return &random{rand: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+// Returns a random allocator with the given seed that has drawn the given
+// number of ranks
+func restoreRandom(seed int64, draws int) *random {
+	r := newRandom(seed).(*random)
+	for r.draws < draws {
+		r.Rank(nil)
+	}
+	return r
+}
+
+func (r *random) Rank(slot *Slot) int {
+	r.draws++
+	return r.rand.Int()
+}
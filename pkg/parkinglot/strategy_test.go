@@ -0,0 +1,95 @@
+package parkinglot
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAllocatorStrategies(t *testing.T) {
+	tests := []struct {
+		name string
+		want []int // Slots of four cars, after the first one leaves before the third one parks
+	}{
+		{
+			name: "nearest_entry",
+			want: []int{1, 2, 1, 3},
+		},
+		{
+			name: "nearest_exit",
+			want: []int{2, 1, 2, 4},
+		},
+		{
+			name: "fill_from_back",
+			want: []int{4, 3, 4, 2},
+		},
+		{
+			name: "round_robin",
+			want: []int{1, 2, 3, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newAllocator, err := LookupAllocator(tt.name)
+			if err != nil {
+				t.Fatalf("LookupAllocator() error = %v", err)
+			}
+			pl := &ParkingLot{}
+			if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", StackedFloors([]int{2, 2}), ExactSize, newAllocator); err != nil {
+				t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+			}
+
+			var got []int
+			for i := 0; i < 4; i++ {
+				if i == 2 {
+					if _, err := pl.Leave(got[0]); err != nil {
+						t.Fatalf("Leave() error = %v", err)
+					}
+				}
+				ticket, err := pl.Park(generateVehicle(i))
+				if err != nil {
+					t.Fatalf("Park() error = %v", err)
+				}
+				got = append(got, ticket.Slots()[0].SlotNumber())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Park() slots = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomAllocator(t *testing.T) {
+	pl := &ParkingLot{}
+	newAllocator := func(slots []*Slot) Allocator { return newRandom(1) }
+	if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", StackedFloors([]int{3, 3}), ExactSize, newAllocator); err != nil {
+		t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+	}
+
+	var got []int
+	for i := 0; i < 6; i++ {
+		ticket, err := pl.Park(generateVehicle(i))
+		if err != nil {
+			t.Fatalf("Park() error = %v", err)
+		}
+		got = append(got, ticket.Slots()[0].SlotNumber())
+	}
+	sort.Ints(got)
+	if want := []int{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Park() slots = %v, want = %v", got, want)
+	}
+	if _, err := pl.Park(generateVehicle(6)); err == nil {
+		t.Errorf("Park() error = %v, wantErr = %v", err, true)
+	}
+}
+
+func TestLookupAllocator(t *testing.T) {
+	for _, name := range []string{"nearest_entry", "Wear_Levelling", "random"} {
+		if _, err := LookupAllocator(name); err != nil {
+			t.Errorf("LookupAllocator(%q) error = %v", name, err)
+		}
+	}
+	if _, err := LookupAllocator("cheapest"); err == nil {
+		t.Errorf("LookupAllocator() error = %v, wantErr = %v", err, true)
+	}
+}
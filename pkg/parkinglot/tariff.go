@@ -0,0 +1,213 @@
+package parkinglot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Rates are the prices of a tariff, in cents
+type Rates struct {
+	firstHour int64 // Price of the first hour or part of it
+	hourly    int64 // Price of every following hour or part of it
+	dailyMax  int64 // Most charged for any 24 hours, no maximum if zero
+	overnight int64 // Flat price of a stay within the overnight period, none if zero
+}
+
+// A Tariff decides the fee for a stay in the parking lot
+type Tariff struct {
+	gracePeriod    time.Duration // Stays up to the grace period are free
+	overnightStart time.Duration // Start of the overnight period, since midnight
+	overnightEnd   time.Duration // End of the overnight period, since midnight
+	rates          Rates
+	vehicleRates   map[VehicleType]Rates // Default rates with the prices given for a vehicle type
+}
+
+// The tariff config file format. Prices are in cents, durations are strings
+// such as "15m" and times of day are strings such as "22:00".
+type tariffConfig struct {
+	GracePeriod    string                 `json:"grace_period"`
+	OvernightStart string                 `json:"overnight_start"`
+	OvernightEnd   string                 `json:"overnight_end"`
+	FirstHour      int64                  `json:"first_hour"`
+	Hourly         int64                  `json:"hourly"`
+	DailyMax       int64                  `json:"daily_max"`
+	Overnight      int64                  `json:"overnight"`
+	VehicleTypes   map[string]ratesConfig `json:"vehicle_types"`
+}
+
+// The prices of a vehicle type, nil for those it takes from the default rates
+type ratesConfig struct {
+	FirstHour *int64 `json:"first_hour"`
+	Hourly    *int64 `json:"hourly"`
+	DailyMax  *int64 `json:"daily_max"`
+	Overnight *int64 `json:"overnight"`
+}
+
+// Returns the default rates with the prices given in the config
+func (c ratesConfig) mergeRates(rates Rates) Rates {
+	if c.FirstHour != nil {
+		rates.firstHour = *c.FirstHour
+	}
+	if c.Hourly != nil {
+		rates.hourly = *c.Hourly
+	}
+	if c.DailyMax != nil {
+		rates.dailyMax = *c.DailyMax
+	}
+	if c.Overnight != nil {
+		rates.overnight = *c.Overnight
+	}
+	return rates
+}
+
+// Returns an error if any price is negative
+func (r Rates) validate() error {
+	if r.firstHour < 0 || r.hourly < 0 || r.dailyMax < 0 || r.overnight < 0 {
+		return fmt.Errorf("Invalid tariff: prices must not be negative")
+	}
+	return nil
+}
+
+// Load a tariff from a JSON config file
+func LoadTariff(path string) (*Tariff, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTariff(data)
+}
+
+// Parse a tariff from JSON
+func parseTariff(data []byte) (*Tariff, error) {
+	var config tariffConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("Invalid tariff: %v", err)
+	}
+
+	tariff := &Tariff{
+		rates: Rates{
+			firstHour: config.FirstHour,
+			hourly:    config.Hourly,
+			dailyMax:  config.DailyMax,
+			overnight: config.Overnight,
+		},
+		vehicleRates: make(map[VehicleType]Rates),
+	}
+	if err := tariff.rates.validate(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if config.GracePeriod != "" {
+		if tariff.gracePeriod, err = time.ParseDuration(config.GracePeriod); err != nil {
+			return nil, fmt.Errorf("Invalid tariff grace period: %v", err)
+		}
+		if tariff.gracePeriod < 0 {
+			return nil, fmt.Errorf("Invalid tariff grace period: %v", config.GracePeriod)
+		}
+	}
+	if config.OvernightStart != "" || config.OvernightEnd != "" {
+		if tariff.overnightStart, err = parseTimeOfDay(config.OvernightStart); err != nil {
+			return nil, err
+		}
+		if tariff.overnightEnd, err = parseTimeOfDay(config.OvernightEnd); err != nil {
+			return nil, err
+		}
+	}
+	for name, rates := range config.VehicleTypes {
+		vehicleType, err := ParseVehicleType(name)
+		if err != nil {
+			return nil, err
+		}
+		merged := rates.mergeRates(tariff.rates)
+		if err := merged.validate(); err != nil {
+			return nil, err
+		}
+		tariff.vehicleRates[vehicleType] = merged
+	}
+
+	return tariff, nil
+}
+
+// Parse a time of day such as "22:00" into the time since midnight
+func parseTimeOfDay(input string) (time.Duration, error) {
+	t, err := time.Parse("15:04", input)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid time of day: %v", input)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Returns the rates for a vehicle type
+func (t *Tariff) getRates(vehicleType VehicleType) Rates {
+	if rates, ok := t.vehicleRates[vehicleType]; ok {
+		return rates
+	}
+	return t.rates
+}
+
+// Get the fee in cents for a vehicle parked from entry until exit time
+func (t *Tariff) getFee(vehicleType VehicleType, entryTime, exitTime time.Time) int64 {
+	stay := exitTime.Sub(entryTime)
+	if stay <= t.gracePeriod {
+		return 0
+	}
+	rates := t.getRates(vehicleType)
+
+	// Every full day is charged up to the daily maximum, followed by the rest
+	// of the stay
+	days := int64(stay / (24 * time.Hour))
+	fee := days*rates.getCappedFee(24*time.Hour) + rates.getCappedFee(stay%(24*time.Hour))
+
+	if rates.overnight > 0 && rates.overnight < fee && t.isOvernight(entryTime, exitTime) {
+		return rates.overnight
+	}
+	return fee
+}
+
+// Reports whether a stay falls within a single overnight period
+func (t *Tariff) isOvernight(entryTime, exitTime time.Time) bool {
+	if t.overnightStart == t.overnightEnd {
+		return false
+	}
+
+	midnight := time.Date(entryTime.Year(), entryTime.Month(), entryTime.Day(), 0, 0, 0, 0, entryTime.Location())
+	timeOfDay := entryTime.Sub(midnight)
+
+	var end time.Time
+	switch {
+	case t.overnightStart < t.overnightEnd && timeOfDay >= t.overnightStart && timeOfDay < t.overnightEnd:
+		end = midnight.Add(t.overnightEnd)
+	case t.overnightStart > t.overnightEnd && timeOfDay >= t.overnightStart:
+		end = midnight.AddDate(0, 0, 1).Add(t.overnightEnd)
+	case t.overnightStart > t.overnightEnd && timeOfDay < t.overnightEnd:
+		end = midnight.Add(t.overnightEnd)
+	default:
+		return false
+	}
+
+	return !exitTime.After(end)
+}
+
+// Get the fee for a stay of up to 24 hours, capped at the daily maximum
+func (r Rates) getCappedFee(stay time.Duration) int64 {
+	if stay <= 0 {
+		return 0
+	}
+
+	// Every started hour is charged
+	hours := int64((stay + time.Hour - 1) / time.Hour)
+	fee := r.firstHour + (hours-1)*r.hourly
+
+	if r.dailyMax > 0 && fee > r.dailyMax {
+		return r.dailyMax
+	}
+	return fee
+}
+
+// Format a fee in cents for display
+func FormatFee(fee int64) string {
+	return fmt.Sprintf("%d.%02d", fee/100, fee%100)
+}
@@ -0,0 +1,168 @@
+package parkinglot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTariffGetFee(t *testing.T) {
+	tariff, err := LoadTariff("../../test/tariff.json")
+	if err != nil {
+		t.Fatalf("LoadTariff() error = %v", err)
+	}
+
+	morning := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	night := time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		vehicleType VehicleType
+		entryTime   time.Time
+		stay        time.Duration
+		want        int64
+	}{
+		{
+			name:        "Within the grace period",
+			vehicleType: Car,
+			entryTime:   morning,
+			stay:        15 * time.Minute,
+			want:        0,
+		},
+		{
+			name:        "Just over the grace period",
+			vehicleType: Car,
+			entryTime:   morning,
+			stay:        16 * time.Minute,
+			want:        300,
+		},
+		{
+			name:        "Every started hour is charged",
+			vehicleType: Car,
+			entryTime:   morning,
+			stay:        2*time.Hour + 30*time.Minute,
+			want:        300 + 2*200,
+		},
+		{
+			name:        "Capped at the daily maximum",
+			vehicleType: Car,
+			entryTime:   morning,
+			stay:        12 * time.Hour,
+			want:        2000,
+		},
+		{
+			name:        "Full days and the rest of the stay",
+			vehicleType: Car,
+			entryTime:   morning,
+			stay:        49 * time.Hour,
+			want:        2*2000 + 300,
+		},
+		{
+			name:        "Overnight stay pays the flat rate",
+			vehicleType: Car,
+			entryTime:   night,
+			stay:        7 * time.Hour,
+			want:        500,
+		},
+		{
+			name:        "Short overnight stay pays by the hour when cheaper",
+			vehicleType: Car,
+			entryTime:   night,
+			stay:        time.Hour,
+			want:        300,
+		},
+		{
+			name:        "Stay past the overnight period",
+			vehicleType: Car,
+			entryTime:   night,
+			stay:        9 * time.Hour,
+			want:        300 + 8*200,
+		},
+		{
+			name:        "Vehicle type rates",
+			vehicleType: Motorcycle,
+			entryTime:   morning,
+			stay:        3 * time.Hour,
+			want:        100 + 2*50,
+		},
+		{
+			name:        "Vehicle type pays by the hour when cheaper than the default overnight rate",
+			vehicleType: Motorcycle,
+			entryTime:   night,
+			stay:        7 * time.Hour,
+			want:        100 + 6*50,
+		},
+		{
+			name:        "Vehicle type overnight rate",
+			vehicleType: Bus,
+			entryTime:   night,
+			stay:        7 * time.Hour,
+			want:        2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tariff.getFee(tt.vehicleType, tt.entryTime, tt.entryTime.Add(tt.stay))
+			if got != tt.want {
+				t.Errorf("Fee() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTariff(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "Minimal tariff", input: `{"first_hour": 300}`, wantErr: false},
+		{name: "Invalid JSON", input: `{"first_hour": }`, wantErr: true},
+		{name: "Invalid grace period", input: `{"grace_period": "soon"}`, wantErr: true},
+		{name: "Invalid overnight period", input: `{"overnight_start": "22:00"}`, wantErr: true},
+		{name: "Unknown vehicle type", input: `{"vehicle_types": {"tank": {}}}`, wantErr: true},
+		{name: "Negative price", input: `{"first_hour": 300, "daily_max": -1}`, wantErr: true},
+		{name: "Negative vehicle type price", input: `{"vehicle_types": {"bus": {"hourly": -5}}}`, wantErr: true},
+		{name: "Negative grace period", input: `{"grace_period": "-15m"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTariff([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTariff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Prices left out for a vehicle type are taken from the default rates
+func TestTariffVehicleTypeRates(t *testing.T) {
+	tariff, err := parseTariff([]byte(`{
+		"first_hour": 300, "hourly": 200, "daily_max": 2000, "overnight": 500,
+		"vehicle_types": {"van": {"first_hour": 400}, "bus": {"daily_max": 0}}
+	}`))
+	if err != nil {
+		t.Fatalf("parseTariff() error = %v", err)
+	}
+
+	tests := []struct {
+		vehicleType VehicleType
+		want        Rates
+	}{
+		{vehicleType: Car, want: Rates{firstHour: 300, hourly: 200, dailyMax: 2000, overnight: 500}},
+		{vehicleType: Van, want: Rates{firstHour: 400, hourly: 200, dailyMax: 2000, overnight: 500}},
+		{vehicleType: Bus, want: Rates{firstHour: 300, hourly: 200, dailyMax: 0, overnight: 500}},
+	}
+	for _, tt := range tests {
+		if got := tariff.getRates(tt.vehicleType); got != tt.want {
+			t.Errorf("getRates(%v) got = %+v, want = %+v", tt.vehicleType, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFee(t *testing.T) {
+	if got := FormatFee(1205); got != "12.05" {
+		t.Errorf("FormatFee() got = %v, want %v", got, "12.05")
+	}
+}
@@ -0,0 +1,70 @@
+package parkinglot
+
+import (
+	"time"
+)
+
+// A Ticket is issued when a vehicle parks and closed when it leaves
+type Ticket struct {
+	ticketNumber int
+	vehicle      *Vehicle
+	entryTime    time.Time
+	exitTime     time.Time // Zero while the ticket is open
+	fee          int64     // Fee in cents charged when the ticket was closed
+	assigned     []*Ticket // Tickets of the waiting vehicles given the freed slots
+}
+
+// Issue a new ticket for a parked vehicle
+func issueTicket(ticketNumber int, vehicle *Vehicle, entryTime time.Time) *Ticket {
+	return &Ticket{ticketNumber: ticketNumber, vehicle: vehicle, entryTime: entryTime}
+}
+
+// Close the ticket when the vehicle leaves
+func (t *Ticket) close(exitTime time.Time, fee int64) {
+	t.exitTime = exitTime
+	t.fee = fee
+}
+
+// Returns the tickets of the waiting vehicles given the slots freed when the
+// vehicle left
+func (t *Ticket) Assigned() []*Ticket {
+	return t.assigned
+}
+
+func (t *Ticket) TicketNumber() int {
+	return t.ticketNumber
+}
+
+func (t *Ticket) Vehicle() *Vehicle {
+	return t.vehicle
+}
+
+// Returns the slots the vehicle was given
+func (t *Ticket) Slots() []*Slot {
+	return t.vehicle.Slots()
+}
+
+func (t *Ticket) EntryTime() time.Time {
+	return t.entryTime
+}
+
+func (t *Ticket) ExitTime() time.Time {
+	return t.exitTime
+}
+
+// Returns the fee in cents charged when the ticket was closed
+func (t *Ticket) Fee() int64 {
+	return t.fee
+}
+
+func (t *Ticket) IsClosed() bool {
+	return !t.exitTime.IsZero()
+}
+
+// Returns how long the vehicle was parked, or zero while the ticket is open
+func (t *Ticket) Duration() time.Duration {
+	if !t.IsClosed() {
+		return 0
+	}
+	return t.exitTime.Sub(t.entryTime)
+}
@@ -0,0 +1,47 @@
+package parkinglot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicketClose(t *testing.T) {
+	vehicle := NewVehicle("KA-01-HH-1234", "White", Car, 0)
+	entryTime := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		exitTime     time.Time
+		wantClosed   bool
+		wantDuration time.Duration
+	}{
+		{
+			name:         "Open ticket",
+			exitTime:     time.Time{},
+			wantClosed:   false,
+			wantDuration: 0,
+		},
+		{
+			name:         "Closed ticket",
+			exitTime:     entryTime.Add(2*time.Hour + 30*time.Minute),
+			wantClosed:   true,
+			wantDuration: 2*time.Hour + 30*time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := issueTicket(1, vehicle, entryTime)
+			if !tt.exitTime.IsZero() {
+				ticket.close(tt.exitTime, 0)
+			}
+
+			if got := ticket.IsClosed(); got != tt.wantClosed {
+				t.Errorf("IsClosed() got = %v, want %v", got, tt.wantClosed)
+			}
+			if got := ticket.Duration(); got != tt.wantDuration {
+				t.Errorf("Duration() got = %v, want %v", got, tt.wantDuration)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package parkinglot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A VehicleType determines the size of slot a vehicle needs
+type VehicleType int
+
+const (
+	Motorcycle VehicleType = iota
+	Car
+	Van
+	Bus
+	Trailer
+)
+
+var vehicleTypeNames = []string{"motorcycle", "car", "van", "bus", "trailer"}
+
+// Slot size needed by each vehicle type
+var vehicleTypeSizes = []SlotSize{Small, Medium, Large, Large, Large}
+
+// Number of adjacent slots needed by each vehicle type
+var vehicleTypeSpans = []int{1, 1, 1, 2, 3}
+
+// Parse a vehicle type name, such as "car"
+func ParseVehicleType(name string) (VehicleType, error) {
+	for i, typeName := range vehicleTypeNames {
+		if strings.EqualFold(name, typeName) {
+			return VehicleType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown vehicle type: %v", name)
+}
+
+func (t VehicleType) String() string {
+	if !t.isValid() {
+		return fmt.Sprintf("VehicleType(%d)", int(t))
+	}
+	return vehicleTypeNames[t]
+}
+
+// Reports whether the vehicle type is one of the known vehicle types
+func (t VehicleType) isValid() bool {
+	return t >= 0 && int(t) < len(vehicleTypeNames)
+}
+
+// Returns the smallest slot size the vehicle type fits in
+func (t VehicleType) getSlotSize() SlotSize {
+	return vehicleTypeSizes[t]
+}
+
+// Returns the number of adjacent slots the vehicle type needs
+func (t VehicleType) getSpan() int {
+	return vehicleTypeSpans[t]
+}
+
+type Vehicle struct {
+	registrationNumber string
+	color              string
+	vehicleType        VehicleType
+	needs              Attributes // Attributes the slot must have, such as an EV charger
+	slots              []*Slot    // Slots the vehicle is parked in, ordered by slot number
+	ticket             *Ticket    // Ticket issued when the vehicle parked
+	entryTime          time.Time  // Time the vehicle parked
+	permit             *Permit    // Permit valid when the vehicle arrived, nil if it has none
+}
+
+// Create a new vehicle
+func NewVehicle(registrationNumber, color string, vehicleType VehicleType, needs Attributes) *Vehicle {
+	return &Vehicle{registrationNumber: registrationNumber, color: color, vehicleType: vehicleType, needs: needs}
+}
+
+// Returns vehicle registration number
+func (v *Vehicle) RegistrationNumber() string {
+	return v.registrationNumber
+}
+
+// Returns vehicle color
+func (v *Vehicle) Color() string {
+	return v.color
+}
+
+// Returns vehicle type
+func (v *Vehicle) Type() VehicleType {
+	return v.vehicleType
+}
+
+// Returns the attributes the vehicle needs in a slot
+func (v *Vehicle) Needs() Attributes {
+	return v.needs
+}
+
+// Returns the slots the vehicle is parked in
+func (v *Vehicle) Slots() []*Slot {
+	return v.slots
+}
+
+// Returns the ticket issued when the vehicle parked
+func (v *Vehicle) Ticket() *Ticket {
+	return v.ticket
+}
+
+// Returns the permit valid when the vehicle arrived, nil if it has none
+func (v *Vehicle) Permit() *Permit {
+	return v.permit
+}
+
+// Reports whether the vehicle arrived with a valid permit
+func (v *Vehicle) IsPermitHolder() bool {
+	return v.permit != nil
+}
+
+// Returns the time the vehicle parked
+func (v *Vehicle) EntryTime() time.Time {
+	return v.entryTime
+}
@@ -0,0 +1,98 @@
+package parkinglot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCreateVehicle(t *testing.T) {
+	type args struct {
+		registrationNumber string
+		color              string
+		vehicleType        VehicleType
+		needs              Attributes
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Vehicle
+	}{
+		{
+			name: "Create vehicle",
+			args: args{
+				registrationNumber: "KA-01-HH-1234",
+				color:              "White",
+				vehicleType:        Car,
+				needs:              EVCharger,
+			},
+			want: &Vehicle{
+				registrationNumber: "KA-01-HH-1234",
+				color:              "White",
+				vehicleType:        Car,
+				needs:              EVCharger,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewVehicle(tt.args.registrationNumber, tt.args.color, tt.args.vehicleType, tt.args.needs)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewVehicle() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVehicleType(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    VehicleType
+		wantErr bool
+	}{
+		{name: "Lower case", input: "motorcycle", want: Motorcycle},
+		{name: "Mixed case", input: "Van", want: Van},
+		{name: "Unknown type", input: "tank", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVehicleType(tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseVehicleType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseVehicleType() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Vehicles of a type that is not one of the known vehicle types are turned
+// away by every way of parking and reserving
+func TestUnknownVehicleType(t *testing.T) {
+	pl, _ := createParkingLotWithClock(t, 3)
+	unknown := VehicleType(99)
+
+	if got := unknown.String(); got != "VehicleType(99)" {
+		t.Errorf("String() got = %v, want = %v", got, "VehicleType(99)")
+	}
+	if _, err := pl.Park(NewVehicle("KA-01-HH-1234", "White", unknown, 0)); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("Park() error = %v, want = %v", err, ErrUnknownVehicleType)
+	}
+	if _, err := pl.ParkAtGate(NewVehicle("KA-01-HH-1234", "White", VehicleType(-1), 0), ""); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("ParkAtGate() error = %v, want = %v", err, ErrUnknownVehicleType)
+	}
+	if _, err := pl.OverridePark(NewVehicle("KA-01-HH-1234", "White", unknown, 0), "", "Cloned plate"); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("OverridePark() error = %v, want = %v", err, ErrUnknownVehicleType)
+	}
+	if _, err := pl.Reserve("KA-01-HH-1234", unknown, testTime.Add(time.Hour), time.Hour, 0); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("Reserve() error = %v, want = %v", err, ErrUnknownVehicleType)
+	}
+}
@@ -0,0 +1,74 @@
+package parkinglot
+
+import (
+	"time"
+)
+
+// A WaitEntry is a vehicle waiting for a slot
+type WaitEntry struct {
+	vehicle  *Vehicle
+	gate     string // Gate the vehicle waits at, if any
+	priority bool   // Permit holders are served before other vehicles
+	since    time.Time
+}
+
+func (e *WaitEntry) Vehicle() *Vehicle {
+	return e.vehicle
+}
+
+func (e *WaitEntry) IsPriority() bool {
+	return e.priority
+}
+
+func (e *WaitEntry) Since() time.Time {
+	return e.since
+}
+
+// A waitlist holds the vehicles waiting for a slot while the parking lot is
+// full. Permit holders are served first, and each tier first come, first
+// served.
+type waitlist struct {
+	priority []*WaitEntry
+	general  []*WaitEntry
+}
+
+// Add a vehicle to the end of its tier. Returns its position in the waitlist,
+// starting from 1.
+func (w *waitlist) add(entry *WaitEntry) int {
+	if entry.priority {
+		w.priority = append(w.priority, entry)
+		return len(w.priority)
+	}
+	w.general = append(w.general, entry)
+	return len(w.priority) + len(w.general)
+}
+
+// Remove the vehicle with a registration number. Reports whether it was waiting.
+func (w *waitlist) remove(registrationNumber string) bool {
+	for _, tier := range []*[]*WaitEntry{&w.priority, &w.general} {
+		for i, entry := range *tier {
+			if entry.vehicle.RegistrationNumber() == registrationNumber {
+				*tier = append((*tier)[:i:i], (*tier)[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Get the waiting vehicles in the order they are served
+func (w *waitlist) getEntries() []*WaitEntry {
+	var entries []*WaitEntry
+	entries = append(entries, w.priority...)
+	return append(entries, w.general...)
+}
+
+// Reports whether a vehicle with a registration number is waiting
+func (w *waitlist) contains(registrationNumber string) bool {
+	for _, entry := range w.getEntries() {
+		if entry.vehicle.RegistrationNumber() == registrationNumber {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package parkinglot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Get the registration numbers of the waiting vehicles in the order they are served
+func getWaitingNumbers(entries []*WaitEntry) []string {
+	var numbers []string
+	for _, entry := range entries {
+		numbers = append(numbers, entry.Vehicle().RegistrationNumber())
+	}
+	return numbers
+}
+
+func TestWaitlist(t *testing.T) {
+	var w waitlist
+	for i, permit := range []bool{false, true, false, true} {
+		vehicle := generateVehicle(i)
+		position := w.add(&WaitEntry{vehicle: vehicle, priority: permit})
+		if want := []int{1, 1, 3, 2}[i]; position != want {
+			t.Errorf("add() position = %v, want = %v", position, want)
+		}
+	}
+
+	want := []string{"KA-01-HH-0001", "KA-01-HH-0003", "KA-01-HH-0000", "KA-01-HH-0002"}
+	if got := getWaitingNumbers(w.getEntries()); !reflect.DeepEqual(got, want) {
+		t.Errorf("getEntries() got = %v, want = %v", got, want)
+	}
+
+	if !w.remove("KA-01-HH-0003") || w.remove("KA-01-HH-0003") {
+		t.Errorf("remove() removed the vehicle other than once")
+	}
+	if !w.contains("KA-01-HH-0000") || w.contains("KA-01-HH-0003") {
+		t.Errorf("contains() got the waiting vehicles wrong")
+	}
+}
+
+func TestServeWaitlist(t *testing.T) {
+	pl := &ParkingLot{}
+	if err := pl.createMultiStoreyParkingLot("Marina Bay Sands", []FloorLayout{{Capacity: 3, Sizes: []SlotSize{Large, Large, Medium}}}, ExactSize, nil); err != nil {
+		t.Fatalf("createMultiStoreyParkingLot() error = %v", err)
+	}
+	pl.enableWaitlist()
+
+	// Two vans fill the large slots and a car the medium one
+	for _, vehicle := range []*Vehicle{
+		NewVehicle("KA-01-HH-0001", "White", Van, 0),
+		NewVehicle("KA-01-HH-0002", "White", Van, 0),
+		NewVehicle("KA-01-HH-0003", "White", Car, 0),
+	} {
+		if _, err := pl.Park(vehicle); err != nil {
+			t.Fatalf("Park() error = %v", err)
+		}
+	}
+
+	// A bus waits ahead of a van and a car
+	for i, vehicle := range []*Vehicle{
+		NewVehicle("KA-01-HH-0004", "White", Bus, 0),
+		NewVehicle("KA-01-HH-0005", "White", Van, 0),
+		NewVehicle("KA-01-HH-0006", "White", Car, 0),
+	} {
+		_, err := pl.Park(vehicle)
+		var waiting *WaitingError
+		if !errors.As(err, &waiting) || waiting.Position != i+1 {
+			t.Fatalf("Park() error = %v, want waitlist position %v", err, i+1)
+		}
+	}
+
+	// The bus does not fit the freed large slot, so the van behind it takes it
+	ticket, err := pl.Leave(1)
+	if err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if got := ticket.Assigned(); len(got) != 1 || got[0].Vehicle().RegistrationNumber() != "KA-01-HH-0005" {
+		t.Errorf("Leave() assigned = %v, want the van", got)
+	}
+	entries, _ := pl.Waitlist()
+	if got, want := getWaitingNumbers(entries), []string{"KA-01-HH-0004", "KA-01-HH-0006"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Waitlist() got = %v, want = %v", got, want)
+	}
+
+	if err := pl.CancelWait("KA-01-HH-0004"); err != nil {
+		t.Errorf("CancelWait() error = %v", err)
+	}
+	if err := pl.CancelWait("KA-01-HH-0004"); err == nil {
+		t.Errorf("CancelWait() error = %v, wantErr = %v", err, true)
+	}
+}
+
+// Waiting vehicles are served whenever a slot is freed, however it is freed,
+// and a vehicle arriving after them waits behind them
+func TestServeWaitlistOnRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		hold    func(pl *ParkingLot) error // Hold the only slot
+		release func(pl *ParkingLot, clock *FakeClock) error
+	}{
+		{
+			name: "Revoked permit",
+			hold: func(pl *ParkingLot) error {
+				_, err := pl.AddPermit("KA-01-HH-0001", testTime, testTime, 1)
+				return err
+			},
+			release: func(pl *ParkingLot, clock *FakeClock) error {
+				return pl.RevokePermit("KA-01-HH-0001")
+			},
+		},
+		{
+			name: "Expired permit",
+			hold: func(pl *ParkingLot) error {
+				_, err := pl.AddPermit("KA-01-HH-0001", testTime, testTime, 1)
+				return err
+			},
+			release: func(pl *ParkingLot, clock *FakeClock) error {
+				clock.Advance(24 * time.Hour)
+				return nil
+			},
+		},
+		{
+			name: "Cancelled reservation",
+			hold: func(pl *ParkingLot) error {
+				_, err := pl.Reserve("KA-01-HH-0001", Car, testTime, time.Hour, 1)
+				return err
+			},
+			release: func(pl *ParkingLot, clock *FakeClock) error {
+				return pl.CancelReservation(1)
+			},
+		},
+		{
+			name: "No-show",
+			hold: func(pl *ParkingLot) error {
+				_, err := pl.Reserve("KA-01-HH-0001", Car, testTime, time.Hour, 1)
+				return err
+			},
+			release: func(pl *ParkingLot, clock *FakeClock) error {
+				clock.Advance(DefaultNoShowAfter)
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl, clock := createParkingLotWithClock(t, 1)
+			pl.enableWaitlist()
+			if err := tt.hold(pl); err != nil {
+				t.Fatalf("holding the slot error = %v", err)
+			}
+			var waiting *WaitingError
+			if _, err := pl.Park(generateVehicle(2)); !errors.As(err, &waiting) {
+				t.Fatalf("Park() error = %v, want to wait", err)
+			}
+
+			if err := tt.release(pl, clock); err != nil {
+				t.Fatalf("releasing the slot error = %v", err)
+			}
+			if _, err := pl.Park(generateVehicle(3)); !errors.As(err, &waiting) || waiting.Position != 1 {
+				t.Errorf("Park() of a newcomer error = %v, want waitlist position 1", err)
+			}
+			if got := pl.Slot(1).Vehicle(); got == nil || got.RegistrationNumber() != "KA-01-HH-0002" {
+				t.Errorf("Slot(1).Vehicle() got = %v, want the waiting vehicle", got)
+			}
+			entries, _ := pl.Waitlist()
+			if got, want := getWaitingNumbers(entries), []string{"KA-01-HH-0003"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("Waitlist() got = %v, want = %v", got, want)
+			}
+		})
+	}
+}